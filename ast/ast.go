@@ -1,7 +1,11 @@
 package ast
 
+import "github.com/AlyxPink/meowlang/token"
+
 type Node interface {
 	TokenLiteral() string
+	Pos() token.Position // position of the first character of the node
+	End() token.Position // position just past the last character of the node
 }
 
 type Statement interface {
@@ -25,3 +29,17 @@ func (p *Program) TokenLiteral() string {
 		return ""
 	}
 }
+
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+
+func (p *Program) End() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[len(p.Statements)-1].End()
+	}
+	return token.Position{}
+}
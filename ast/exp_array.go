@@ -0,0 +1,24 @@
+package ast
+
+import "github.com/AlyxPink/meowlang/token"
+
+// ArrayLiteral is a list of elements, e.g. [1, 2, 3].
+type ArrayLiteral struct {
+	Token    token.Token // the '[' token
+	Elements []Expression
+	RBracket token.Token // the ']' token
+}
+
+func (al *ArrayLiteral) expressionNode() {}
+
+func (al *ArrayLiteral) TokenLiteral() string {
+	return al.Token.Literal
+}
+
+func (al *ArrayLiteral) Pos() token.Position {
+	return al.Token.Pos
+}
+
+func (al *ArrayLiteral) End() token.Position {
+	return al.RBracket.End
+}
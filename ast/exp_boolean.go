@@ -0,0 +1,24 @@
+package ast
+
+import (
+	"github.com/AlyxPink/meowlang/token"
+)
+
+type BooleanLiteral struct {
+	Token token.Token // the token.TRUE or token.FALSE token
+	Value bool
+}
+
+func (bl *BooleanLiteral) expressionNode() {}
+
+func (bl *BooleanLiteral) TokenLiteral() string {
+	return bl.Token.Literal
+}
+
+func (bl *BooleanLiteral) Pos() token.Position {
+	return bl.Token.Pos
+}
+
+func (bl *BooleanLiteral) End() token.Position {
+	return bl.Token.End
+}
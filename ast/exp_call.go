@@ -8,6 +8,7 @@ type CallExpression struct {
 	Token     token.Token // The '(' token
 	Function  Expression  // Identifier or FunctionLiteral
 	Arguments []Expression
+	RParen    token.Token // The ')' token
 }
 
 func (ce *CallExpression) expressionNode() {}
@@ -15,3 +16,11 @@ func (ce *CallExpression) expressionNode() {}
 func (ce *CallExpression) TokenLiteral() string {
 	return ce.Token.Literal
 }
+
+func (ce *CallExpression) Pos() token.Position {
+	return ce.Function.Pos()
+}
+
+func (ce *CallExpression) End() token.Position {
+	return ce.RParen.End
+}
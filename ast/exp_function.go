@@ -0,0 +1,24 @@
+package ast
+
+import "github.com/AlyxPink/meowlang/token"
+
+// FunctionLiteral is an anonymous function, e.g. meow(x) { claw x * 2 }.
+type FunctionLiteral struct {
+	Token      token.Token // the token.MEOW token
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (fl *FunctionLiteral) expressionNode() {}
+
+func (fl *FunctionLiteral) TokenLiteral() string {
+	return fl.Token.Literal
+}
+
+func (fl *FunctionLiteral) Pos() token.Position {
+	return fl.Token.Pos
+}
+
+func (fl *FunctionLiteral) End() token.Position {
+	return fl.Body.End()
+}
@@ -0,0 +1,25 @@
+package ast
+
+import "github.com/AlyxPink/meowlang/token"
+
+// IndexExpression accesses an element of a collection, e.g. xs[0].
+type IndexExpression struct {
+	Token    token.Token // the '[' token
+	Left     Expression
+	Index    Expression
+	RBracket token.Token // the ']' token
+}
+
+func (ie *IndexExpression) expressionNode() {}
+
+func (ie *IndexExpression) TokenLiteral() string {
+	return ie.Token.Literal
+}
+
+func (ie *IndexExpression) Pos() token.Position {
+	return ie.Left.Pos()
+}
+
+func (ie *IndexExpression) End() token.Position {
+	return ie.RBracket.End
+}
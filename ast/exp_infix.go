@@ -14,3 +14,11 @@ func (ie *InfixExpression) expressionNode() {}
 func (ie *InfixExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
+
+func (ie *InfixExpression) Pos() token.Position {
+	return ie.Left.Pos()
+}
+
+func (ie *InfixExpression) End() token.Position {
+	return ie.Right.End()
+}
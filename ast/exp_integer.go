@@ -1,12 +1,15 @@
 package ast
 
 import (
+	"math/big"
+
 	"github.com/AlyxPink/meowlang/token"
 )
 
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
+	Big   *big.Int // set instead of Value for literals that do not fit in an int64
 }
 
 func (il *IntegerLiteral) expressionNode() {}
@@ -14,3 +17,11 @@ func (il *IntegerLiteral) expressionNode() {}
 func (il *IntegerLiteral) TokenLiteral() string {
 	return il.Token.Literal
 }
+
+func (il *IntegerLiteral) Pos() token.Position {
+	return il.Token.Pos
+}
+
+func (il *IntegerLiteral) End() token.Position {
+	return il.Token.End
+}
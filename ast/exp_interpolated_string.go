@@ -0,0 +1,27 @@
+package ast
+
+import (
+	"github.com/AlyxPink/meowlang/token"
+)
+
+// InterpolatedString is a string literal with embedded expressions, e.g.
+// "Result: {result}". Its literal parts are held as StringLiterals.
+type InterpolatedString struct {
+	Token token.Token // the token.TEMPLATE_HEAD token
+	Parts []Expression
+	Tail  token.Token // the token.TEMPLATE_TAIL token
+}
+
+func (is *InterpolatedString) expressionNode() {}
+
+func (is *InterpolatedString) TokenLiteral() string {
+	return is.Token.Literal
+}
+
+func (is *InterpolatedString) Pos() token.Position {
+	return is.Token.Pos
+}
+
+func (is *InterpolatedString) End() token.Position {
+	return is.Tail.End
+}
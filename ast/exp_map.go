@@ -0,0 +1,30 @@
+package ast
+
+import "github.com/AlyxPink/meowlang/token"
+
+// MapLiteral is a list of key-value pairs, e.g. {"name": "Mochi", "age": 3}.
+type MapLiteral struct {
+	Token  token.Token // the '{' token
+	Pairs  []MapLiteralPair
+	RBrace token.Token // the '}' token
+}
+
+// MapLiteralPair is a key-value pair of a map literal, in source order.
+type MapLiteralPair struct {
+	Key   Expression
+	Value Expression
+}
+
+func (ml *MapLiteral) expressionNode() {}
+
+func (ml *MapLiteral) TokenLiteral() string {
+	return ml.Token.Literal
+}
+
+func (ml *MapLiteral) Pos() token.Position {
+	return ml.Token.Pos
+}
+
+func (ml *MapLiteral) End() token.Position {
+	return ml.RBrace.End
+}
@@ -0,0 +1,23 @@
+package ast
+
+import "github.com/AlyxPink/meowlang/token"
+
+type PrefixExpression struct {
+	Token    token.Token // The prefix token, e.g., '!'
+	Operator string
+	Right    Expression
+}
+
+func (pe *PrefixExpression) expressionNode() {}
+
+func (pe *PrefixExpression) TokenLiteral() string {
+	return pe.Token.Literal
+}
+
+func (pe *PrefixExpression) Pos() token.Position {
+	return pe.Token.Pos
+}
+
+func (pe *PrefixExpression) End() token.Position {
+	return pe.Right.End()
+}
@@ -13,3 +13,11 @@ func (ls *AssignStatement) statementNode() {}
 func (ls *AssignStatement) TokenLiteral() string {
 	return ls.Token.Literal
 }
+
+func (ls *AssignStatement) Pos() token.Position {
+	return ls.Token.Pos
+}
+
+func (ls *AssignStatement) End() token.Position {
+	return ls.Value.End()
+}
@@ -3,8 +3,9 @@ package ast
 import "github.com/AlyxPink/meowlang/token"
 
 type BlockStatement struct {
-	Token      token.Token
+	Token      token.Token // the '{' token
 	Statements []Statement
+	RBrace     token.Token // the '}' token
 }
 
 func (bs *BlockStatement) statementNode() {}
@@ -12,3 +13,18 @@ func (bs *BlockStatement) statementNode() {}
 func (bs *BlockStatement) TokenLiteral() string {
 	return bs.Token.Literal
 }
+
+func (bs *BlockStatement) Pos() token.Position {
+	return bs.Token.Pos
+}
+
+func (bs *BlockStatement) End() token.Position {
+	if bs.RBrace.Type != "" {
+		return bs.RBrace.End
+	}
+	// 'growl hiss' chains are held by a block without braces
+	if len(bs.Statements) > 0 {
+		return bs.Statements[len(bs.Statements)-1].End()
+	}
+	return bs.Token.End
+}
@@ -0,0 +1,23 @@
+package ast
+
+import "github.com/AlyxPink/meowlang/token"
+
+// DeleteStatement removes an element from a collection, e.g. shoo m["key"].
+type DeleteStatement struct {
+	Token  token.Token // the 'shoo' token
+	Target *IndexExpression
+}
+
+func (ds *DeleteStatement) statementNode() {}
+
+func (ds *DeleteStatement) TokenLiteral() string {
+	return ds.Token.Literal
+}
+
+func (ds *DeleteStatement) Pos() token.Position {
+	return ds.Token.Pos
+}
+
+func (ds *DeleteStatement) End() token.Position {
+	return ds.Target.End()
+}
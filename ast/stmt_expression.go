@@ -0,0 +1,23 @@
+package ast
+
+import "github.com/AlyxPink/meowlang/token"
+
+// ExpressionStatement is a statement consisting of a single expression, e.g. a function call.
+type ExpressionStatement struct {
+	Token      token.Token // the first token of the expression
+	Expression Expression
+}
+
+func (es *ExpressionStatement) statementNode() {}
+
+func (es *ExpressionStatement) TokenLiteral() string {
+	return es.Token.Literal
+}
+
+func (es *ExpressionStatement) Pos() token.Position {
+	return es.Expression.Pos()
+}
+
+func (es *ExpressionStatement) End() token.Position {
+	return es.Expression.End()
+}
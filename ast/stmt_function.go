@@ -16,3 +16,11 @@ func (fs *FunctionStatement) statementNode() {}
 func (fs *FunctionStatement) TokenLiteral() string {
 	return fs.Token.Literal
 }
+
+func (fs *FunctionStatement) Pos() token.Position {
+	return fs.Token.Pos
+}
+
+func (fs *FunctionStatement) End() token.Position {
+	return fs.Body.End()
+}
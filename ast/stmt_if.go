@@ -0,0 +1,27 @@
+package ast
+
+import "github.com/AlyxPink/meowlang/token"
+
+type IfStatement struct {
+	Token       token.Token // the token.HISS token
+	Condition   Expression
+	Consequence *BlockStatement
+	Alternative *BlockStatement // the 'growl' block, nil if absent
+}
+
+func (is *IfStatement) statementNode() {}
+
+func (is *IfStatement) TokenLiteral() string {
+	return is.Token.Literal
+}
+
+func (is *IfStatement) Pos() token.Position {
+	return is.Token.Pos
+}
+
+func (is *IfStatement) End() token.Position {
+	if is.Alternative != nil {
+		return is.Alternative.End()
+	}
+	return is.Consequence.End()
+}
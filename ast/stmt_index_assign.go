@@ -0,0 +1,24 @@
+package ast
+
+import "github.com/AlyxPink/meowlang/token"
+
+// IndexAssignStatement replaces an element of a collection, e.g. xs[0] = 1.
+type IndexAssignStatement struct {
+	Token  token.Token // the token.ASSIGN token
+	Target *IndexExpression
+	Value  Expression
+}
+
+func (ias *IndexAssignStatement) statementNode() {}
+
+func (ias *IndexAssignStatement) TokenLiteral() string {
+	return ias.Token.Literal
+}
+
+func (ias *IndexAssignStatement) Pos() token.Position {
+	return ias.Target.Pos()
+}
+
+func (ias *IndexAssignStatement) End() token.Position {
+	return ias.Value.End()
+}
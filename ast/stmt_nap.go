@@ -0,0 +1,22 @@
+package ast
+
+import "github.com/AlyxPink/meowlang/token"
+
+type NapStatement struct {
+	Token    token.Token // the token.NAP token
+	Duration Expression
+}
+
+func (ns *NapStatement) statementNode() {}
+
+func (ns *NapStatement) TokenLiteral() string {
+	return ns.Token.Literal
+}
+
+func (ns *NapStatement) Pos() token.Position {
+	return ns.Token.Pos
+}
+
+func (ns *NapStatement) End() token.Position {
+	return ns.Duration.End()
+}
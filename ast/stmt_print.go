@@ -12,3 +12,11 @@ func (ps *PrintStatement) statementNode() {}
 func (ps *PrintStatement) TokenLiteral() string {
 	return ps.Token.Literal
 }
+
+func (ps *PrintStatement) Pos() token.Position {
+	return ps.Token.Pos
+}
+
+func (ps *PrintStatement) End() token.Position {
+	return ps.Value.End()
+}
@@ -0,0 +1,23 @@
+package ast
+
+import "github.com/AlyxPink/meowlang/token"
+
+type ReassignStatement struct {
+	Token token.Token // the token.ASSIGN token
+	Name  *Identifier
+	Value Expression
+}
+
+func (rs *ReassignStatement) statementNode() {}
+
+func (rs *ReassignStatement) TokenLiteral() string {
+	return rs.Token.Literal
+}
+
+func (rs *ReassignStatement) Pos() token.Position {
+	return rs.Name.Pos()
+}
+
+func (rs *ReassignStatement) End() token.Position {
+	return rs.Value.End()
+}
@@ -0,0 +1,23 @@
+package ast
+
+import "github.com/AlyxPink/meowlang/token"
+
+type WhileStatement struct {
+	Token     token.Token // the token.SCRATCH token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode() {}
+
+func (ws *WhileStatement) TokenLiteral() string {
+	return ws.Token.Literal
+}
+
+func (ws *WhileStatement) Pos() token.Position {
+	return ws.Token.Pos
+}
+
+func (ws *WhileStatement) End() token.Position {
+	return ws.Body.End()
+}
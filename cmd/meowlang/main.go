@@ -1,37 +1,72 @@
 package main
 
 import (
-	"bytes"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 
-	"github.com/AlyxPink/meowlang/interpreter"
-	"github.com/AlyxPink/meowlang/lexer"
-	"github.com/AlyxPink/meowlang/parser"
+	"github.com/AlyxPink/meowlang/meow"
+	"github.com/AlyxPink/meowlang/object"
+	"github.com/AlyxPink/meowlang/repl"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: meowlang <filename>")
+	backend := flag.String("backend", string(meow.Interpreter), "how to run programs: interpreter or vm")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-backend=interpreter|vm] [file]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := meow.Backend(*backend).Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if flag.NArg() < 1 {
+		r := repl.NewWithWriters(os.Stdin, os.Stdout, os.Stderr)
+		if err := r.SetBackend(meow.Backend(*backend)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		fmt.Println("Welcome to MeowLang! Type :help for help, Ctrl+D to exit.")
+		r.Run()
 		return
 	}
 
-	filename := os.Args[1]
+	filename := flag.Arg(0)
 	content, err := os.ReadFile(filename)
 	if err != nil {
-		fmt.Println("Error reading file:", err)
+		fmt.Fprintln(os.Stderr, "Error reading file:", err)
 		return
 	}
 
-	l := lexer.NewLexer(string(content))
-	tokens := l.Tokenize()
-
-	p := parser.NewParser(tokens)
-	ast := p.ParseProgram()
-
-	var buf bytes.Buffer
-	i := interpreter.NewInterpreterWithOutput(&buf)
-	i.Interpret(ast)
+	program, err := meow.Compile(string(content))
+	if err != nil {
+		var compileErr *meow.CompileError
+		if errors.As(err, &compileErr) {
+			for _, msg := range compileErr.Errors {
+				fmt.Fprintf(os.Stderr, "%s:%s\n", filename, msg)
+			}
+		} else {
+			fmt.Fprintln(os.Stderr, meow.Diagnostic(filename, err))
+		}
+		os.Exit(1)
+	}
 
-	fmt.Print(buf.String())
+	if _, err := meow.Run(context.Background(), program, &meow.Options{
+		Stdout:  os.Stdout,
+		Stderr:  os.Stderr,
+		Name:    filename,
+		Backend: meow.Backend(*backend),
+	}); err != nil {
+		// Run already wrote the runtime errors to Stderr
+		var runtimeErr *object.Error
+		if !errors.As(err, &runtimeErr) {
+			fmt.Fprintln(os.Stderr, meow.Diagnostic(filename, err))
+		}
+		os.Exit(1)
+	}
 }
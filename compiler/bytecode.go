@@ -0,0 +1,107 @@
+package compiler
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/AlyxPink/meowlang/object"
+	"github.com/AlyxPink/meowlang/token"
+)
+
+// Bytecode is the compiled code of a program or of a function.
+type Bytecode struct {
+	Instructions Instructions
+	Constants    []object.Object // the literals and functions, referred to by index
+	Names        []string        // the names of the variables of enclosing functions, referred to by index
+
+	// Globals are the names of the global variables used by a program and by
+	// its functions, referred to by index. The vm resolves them to the slots
+	// of the environment the program runs in once, when it starts running it.
+	Globals []string
+
+	// Locals are the slots of the variables of a function: its parameters
+	// first, in order, then the variables it declares. LocalNames names each slot.
+	Locals     map[string]int
+	LocalNames []string
+
+	// Positions are the tokens of the nodes compiled to instructions that can
+	// fail, by increasing offset, to locate runtime errors.
+	Positions []Position
+
+	// Steps are the nodes evaluated by the code, by increasing offset, see StepsAt.
+	Steps     []Position
+	stepStart []int // stepStart[offset] is the index in Steps of the first step at offset
+}
+
+// Position is the token of a node compiled at an offset of the instructions.
+type Position struct {
+	Offset int
+	Token  token.Token
+}
+
+// Tokens returns the tokens of the instruction at offset, to locate its errors.
+// Most instructions have one, OpDelete has the 'shoo' keyword then the '[' of its target.
+func (b *Bytecode) Tokens(offset int) []token.Token {
+	start := sort.Search(len(b.Positions), func(i int) bool { return b.Positions[i].Offset >= offset })
+
+	var tokens []token.Token
+	for _, position := range b.Positions[start:] {
+		if position.Offset != offset {
+			break
+		}
+		tokens = append(tokens, position.Token)
+	}
+	return tokens
+}
+
+// Token returns the token of the instruction at offset, to locate its errors.
+func (b *Bytecode) Token(offset int) token.Token {
+	i := sort.Search(len(b.Positions), func(i int) bool { return b.Positions[i].Offset >= offset })
+	if i < len(b.Positions) && b.Positions[i].Offset == offset {
+		return b.Positions[i].Token
+	}
+	return token.Token{}
+}
+
+// StepsAt returns the nodes counted by the OpStep at offset, in the order
+// they are evaluated. They count against the step limit as the interpreter
+// counts its steps, so that both stop at the same node.
+func (b *Bytecode) StepsAt(offset int) []Position {
+	if offset+1 >= len(b.stepStart) {
+		return nil
+	}
+	return b.Steps[b.stepStart[offset]:b.stepStart[offset+1]]
+}
+
+// indexSteps computes stepStart, once the instructions are complete.
+func (b *Bytecode) indexSteps() {
+	b.stepStart = make([]int, len(b.Instructions)+2)
+
+	step := 0
+	for offset := range b.stepStart {
+		for step < len(b.Steps) && b.Steps[step].Offset < offset {
+			step++
+		}
+		b.stepStart[offset] = step
+	}
+}
+
+// Function is a function compiled to bytecode, a constant of the code that
+// defines it. The vm creates a closure of it each time the definition runs.
+type Function struct {
+	Parameters []string
+	Code       *Bytecode
+}
+
+func (f *Function) Type() object.ObjectType { return object.FUNCTION_OBJ }
+func (f *Function) Inspect() string {
+	var out bytes.Buffer
+
+	out.WriteString("meow")
+	out.WriteString("(")
+	out.WriteString(strings.Join(f.Parameters, ", "))
+	out.WriteString(") { ... }")
+
+	return out.String()
+}
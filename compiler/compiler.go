@@ -0,0 +1,642 @@
+// Package compiler lowers the AST of a MeowLang program to bytecode, which is
+// run by the vm package.
+//
+// Each function is compiled to its own Bytecode, a constant of the code that
+// defines it. Variables live in the same environments as with the
+// interpreter, so that closures, the REPL and Go code reading them behave the
+// same with both backends, but they are reached by slot rather than by name:
+// the variables of a function are in the slots of the environment of its
+// call, and global variables in slots that the vm looks up once, when it
+// starts running the program.
+package compiler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/object"
+	"github.com/AlyxPink/meowlang/token"
+)
+
+// Compile compiles a program. It also accepts a single statement or
+// expression, compiled as a program made of it.
+func Compile(node ast.Node) (*Bytecode, error) {
+	c := &compiler{globals: make(map[string]int)}
+	c.enterScope()
+
+	var err error
+	switch node := node.(type) {
+	case *ast.Program:
+		c.step(node)
+		for _, stmt := range node.Statements {
+			if err = c.compileStatement(stmt); err != nil {
+				break
+			}
+		}
+	case ast.Statement:
+		err = c.compileStatement(node)
+	case ast.Expression:
+		if err = c.compileExpression(node); err == nil {
+			c.emit(OpPop)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c.leaveScope(), nil
+}
+
+// Error is an error compiling a program, e.g. a program too large for the
+// bytecode. It is located at the token of the node that caused it.
+type Error struct {
+	Message string
+	Token   token.Token
+}
+
+func (e *Error) Error() string {
+	return e.Token.Pos.String() + ": " + e.Message
+}
+
+func newError(tok token.Token, format string, a ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, a...), Token: tok}
+}
+
+// compiler holds the code being compiled, the program first and the innermost function last.
+type compiler struct {
+	scopes  []*scope
+	globals map[string]int // the index of each name in the Globals of the program
+}
+
+// scope is the code of a program or of a function being compiled.
+type scope struct {
+	code      *Bytecode
+	names     map[string]int      // the index of each name in code.Names
+	constants map[constantKey]int // the index of each literal in code.Constants
+	step      int                 // the offset of the OpStep that can count the next steps, or -1
+}
+
+// constantKey identifies the value of a literal, so that a literal used
+// several times is a single constant.
+type constantKey struct {
+	typ   object.ObjectType
+	value string
+}
+
+func (c *compiler) enterScope() {
+	c.scopes = append(c.scopes, &scope{
+		code:      &Bytecode{},
+		names:     make(map[string]int),
+		constants: make(map[constantKey]int),
+		step:      -1,
+	})
+}
+
+func (c *compiler) leaveScope() *Bytecode {
+	code := c.current().code
+	code.indexSteps()
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	return code
+}
+
+func (c *compiler) current() *scope {
+	return c.scopes[len(c.scopes)-1]
+}
+
+// compileStatement compiles a statement, which leaves its value on the stack
+// for OpPop, except 'claw' which returns it.
+func (c *compiler) compileStatement(stmt ast.Statement) error {
+	c.step(stmt)
+
+	switch stmt := stmt.(type) {
+	case *ast.AssignStatement:
+		if err := c.compileExpression(stmt.Value); err != nil {
+			return err
+		}
+		if err := c.emitVariable(stmt.Name.Token, define, stmt.Name.Value); err != nil {
+			return err
+		}
+	case *ast.ReassignStatement:
+		if err := c.compileExpression(stmt.Value); err != nil {
+			return err
+		}
+		if err := c.emitVariable(stmt.Name.Token, assign, stmt.Name.Value); err != nil {
+			return err
+		}
+	case *ast.IndexAssignStatement:
+		if err := c.compileExpressions(stmt.Target.Left, stmt.Target.Index, stmt.Value); err != nil {
+			return err
+		}
+		c.emitAt(stmt.Target.Token, OpSetIndex)
+	case *ast.DeleteStatement:
+		if err := c.compileExpressions(stmt.Target.Left, stmt.Target.Index); err != nil {
+			return err
+		}
+		c.locate(stmt.Token)
+		c.emitAt(stmt.Target.Token, OpDelete)
+	case *ast.FunctionStatement:
+		if err := c.compileFunction(stmt.Token, stmt.Parameters, stmt.Body); err != nil {
+			return err
+		}
+		if err := c.emitVariable(stmt.Name.Token, define, stmt.Name.Value); err != nil {
+			return err
+		}
+	case *ast.ReturnStatement:
+		if err := c.compileExpression(stmt.ReturnValue); err != nil {
+			return err
+		}
+		c.emitAt(stmt.Token, OpReturn)
+		return nil
+	case *ast.PrintStatement:
+		if err := c.compileExpression(stmt.Value); err != nil {
+			return err
+		}
+		c.emitAt(stmt.Token, OpPrint)
+	case *ast.ExpressionStatement:
+		if err := c.compileExpression(stmt.Expression); err != nil {
+			return err
+		}
+	case *ast.BlockStatement:
+		return c.compileBlock(stmt)
+	case *ast.IfStatement:
+		return c.compileIfStatement(stmt)
+	case *ast.WhileStatement:
+		return c.compileWhileStatement(stmt)
+	case *ast.NapStatement:
+		if err := c.compileExpression(stmt.Duration); err != nil {
+			return err
+		}
+		c.emitAt(stmt.Token, OpNap)
+	default:
+		return newError(nodeToken(stmt), "cannot compile %T", stmt)
+	}
+
+	c.emit(OpPop)
+	return nil
+}
+
+// compileBlock compiles the statements of a block. An empty block has no value.
+func (c *compiler) compileBlock(block *ast.BlockStatement) error {
+	if len(block.Statements) == 0 {
+		c.emit(OpUnset)
+		return nil
+	}
+
+	for _, stmt := range block.Statements {
+		if err := c.compileStatement(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileIfStatement compiles a conditional statement. Without an
+// alternative, its value is null when the condition is falsy.
+func (c *compiler) compileIfStatement(stmt *ast.IfStatement) error {
+	if err := c.compileExpression(stmt.Condition); err != nil {
+		return err
+	}
+	jumpNotTruthy := c.emit(OpJumpNotTruthy, 0)
+
+	if err := c.compileBlock(stmt.Consequence); err != nil {
+		return err
+	}
+	jump := c.emit(OpJump, 0)
+
+	if err := c.patchJump(stmt.Token, jumpNotTruthy); err != nil {
+		return err
+	}
+	if stmt.Alternative != nil {
+		if err := c.compileBlock(stmt.Alternative); err != nil {
+			return err
+		}
+	} else {
+		c.emit(OpNull)
+		c.emit(OpPop)
+	}
+
+	return c.patchJump(stmt.Token, jump)
+}
+
+// compileWhileStatement compiles a 'scratch' loop, whose value is null. The
+// condition is compiled after the body, so that each iteration takes a single jump.
+func (c *compiler) compileWhileStatement(stmt *ast.WhileStatement) error {
+	jump := c.emit(OpJump, 0)
+
+	body := c.label()
+	if err := c.compileBlock(stmt.Body); err != nil {
+		return err
+	}
+
+	if err := c.patchJump(stmt.Token, jump); err != nil {
+		return err
+	}
+	if err := c.compileExpression(stmt.Condition); err != nil {
+		return err
+	}
+	c.emit(OpJumpTruthy, body)
+
+	c.emit(OpNull)
+	c.emit(OpPop)
+	return nil
+}
+
+// compileExpression compiles an expression, which pushes its value. A
+// missing expression, e.g. the value of a bare 'claw', is null.
+func (c *compiler) compileExpression(exp ast.Expression) error {
+	c.step(exp)
+
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		return c.emitVariable(exp.Token, get, exp.Value)
+	case *ast.IntegerLiteral:
+		return c.emitConstant(exp.Token, &object.Integer{Value: exp.Value, Big: exp.Big})
+	case *ast.FloatLiteral:
+		return c.emitConstant(exp.Token, &object.Float{Value: exp.Value})
+	case *ast.StringLiteral:
+		return c.emitConstant(exp.Token, &object.String{Value: exp.Value})
+	case *ast.BooleanLiteral:
+		if exp.Value {
+			c.emit(OpTrue)
+		} else {
+			c.emit(OpFalse)
+		}
+	case *ast.InterpolatedString:
+		if err := c.compileExpressions(exp.Parts...); err != nil {
+			return err
+		}
+		return c.emitCount(exp.Token, OpInterpolate, len(exp.Parts))
+	case *ast.ArrayLiteral:
+		if err := c.compileExpressions(exp.Elements...); err != nil {
+			return err
+		}
+		return c.emitCount(exp.Token, OpArray, len(exp.Elements))
+	case *ast.MapLiteral:
+		// Each key is checked before its value is evaluated, like the interpreter does
+		for _, pair := range exp.Pairs {
+			if err := c.compileExpression(pair.Key); err != nil {
+				return err
+			}
+			c.emitAt(nodeToken(pair.Key), OpHashable)
+			if err := c.compileExpression(pair.Value); err != nil {
+				return err
+			}
+		}
+		return c.emitCount(exp.Token, OpMap, len(exp.Pairs))
+	case *ast.FunctionLiteral:
+		return c.compileFunction(exp.Token, exp.Parameters, exp.Body)
+	case *ast.IndexExpression:
+		if err := c.compileExpressions(exp.Left, exp.Index); err != nil {
+			return err
+		}
+		c.emitAt(exp.Token, OpIndex)
+	case *ast.PrefixExpression:
+		if err := c.compileExpression(exp.Right); err != nil {
+			return err
+		}
+		switch exp.Operator {
+		case "-":
+			c.emitAt(exp.Token, OpMinus)
+		case "!":
+			c.emitAt(exp.Token, OpNot)
+		default:
+			return newError(exp.Token, "unknown operator: %s", exp.Operator)
+		}
+	case *ast.InfixExpression:
+		op, ok := infixOpcodes[exp.Operator]
+		if !ok {
+			return newError(exp.Token, "unknown operator: %s", exp.Operator)
+		}
+		if err := c.compileExpressions(exp.Left, exp.Right); err != nil {
+			return err
+		}
+		c.emitAt(exp.Token, op)
+	case *ast.CallExpression:
+		if err := c.compileExpressions(exp.Function); err != nil {
+			return err
+		}
+		if err := c.compileExpressions(exp.Arguments...); err != nil {
+			return err
+		}
+		c.locate(exp.Token)
+		return c.emitCount(exp.Token, OpCall, len(exp.Arguments))
+	default:
+		c.emit(OpNull)
+	}
+	return nil
+}
+
+// infixOpcodes maps infix operators to the opcode applying them.
+var infixOpcodes = map[string]Opcode{
+	"+":  OpAdd,
+	"-":  OpSub,
+	"*":  OpMul,
+	"/":  OpDiv,
+	"==": OpEqual,
+	"!=": OpNotEqual,
+	"<":  OpLess,
+	">":  OpGreater,
+	"<=": OpLessEqual,
+	">=": OpGreaterEqual,
+}
+
+// compileExpressions compiles expressions in order, pushing their values.
+func (c *compiler) compileExpressions(exps ...ast.Expression) error {
+	for _, exp := range exps {
+		if err := c.compileExpression(exp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileFunction compiles a function to its own Bytecode, and emits the
+// creation of a closure of it. A function returns the value of its last
+// statement, unless it returns earlier with 'claw'.
+func (c *compiler) compileFunction(tok token.Token, parameters []*ast.Identifier, body *ast.BlockStatement) error {
+	c.enterScope()
+	fnCode := c.current().code
+	fnCode.Locals = make(map[string]int)
+	for i, param := range parameters {
+		fnCode.Locals[param.Value] = i // the last of parameters with the same name wins, as it is set last
+		fnCode.LocalNames = append(fnCode.LocalNames, param.Value)
+	}
+	for _, name := range declarations(body.Statements) {
+		if _, ok := fnCode.Locals[name]; !ok {
+			fnCode.Locals[name] = len(fnCode.LocalNames)
+			fnCode.LocalNames = append(fnCode.LocalNames, name)
+		}
+	}
+
+	c.step(body)
+	for _, stmt := range body.Statements {
+		if err := c.compileStatement(stmt); err != nil {
+			return err
+		}
+	}
+	c.emit(OpLast)
+	c.emit(OpReturn)
+	code := c.leaveScope()
+
+	params := make([]string, len(parameters))
+	for i, param := range parameters {
+		params[i] = param.Value
+	}
+
+	index, err := c.addConstant(tok, &Function{Parameters: params, Code: code})
+	if err != nil {
+		return err
+	}
+	c.emit(OpClosure, index)
+	return nil
+}
+
+// declarations returns the names of the variables declared by statements,
+// with 'lick' or 'meow', including in the blocks they contain but not in the
+// functions they define.
+func declarations(stmts []ast.Statement) []string {
+	var names []string
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.AssignStatement:
+			names = append(names, stmt.Name.Value)
+		case *ast.FunctionStatement:
+			names = append(names, stmt.Name.Value)
+		case *ast.BlockStatement:
+			names = append(names, declarations(stmt.Statements)...)
+		case *ast.IfStatement:
+			names = append(names, declarations(stmt.Consequence.Statements)...)
+			if stmt.Alternative != nil {
+				names = append(names, declarations(stmt.Alternative.Statements)...)
+			}
+		case *ast.WhileStatement:
+			names = append(names, declarations(stmt.Body.Statements)...)
+		}
+	}
+	return names
+}
+
+// step records the evaluation of a node, counted by an OpStep before the next
+// instruction. Steps are counted by the same OpStep until an instruction that
+// is not pure, see Bytecode.StepsAt.
+func (c *compiler) step(node ast.Node) {
+	scope := c.current()
+	code := scope.code
+	if scope.step < 0 {
+		scope.step = c.emit(OpStep, 0)
+	}
+
+	code.Steps = append(code.Steps, Position{Offset: scope.step, Token: nodeToken(node)})
+	count := int(ReadUint32(code.Instructions[scope.step+1:])) + 1
+	copy(code.Instructions[scope.step:], Make(OpStep, count))
+}
+
+// label returns the offset of the next instruction, the target of a jump.
+// Steps before it and after it are counted separately, as they are not
+// always evaluated together.
+func (c *compiler) label() int {
+	scope := c.current()
+	scope.step = -1
+	return len(scope.code.Instructions)
+}
+
+// locate records tok as a token of the next instruction, see Bytecode.Tokens.
+func (c *compiler) locate(tok token.Token) {
+	code := c.current().code
+	code.Positions = append(code.Positions, Position{Offset: len(code.Instructions), Token: tok})
+}
+
+// emit appends an instruction, and returns its offset.
+func (c *compiler) emit(op Opcode, operands ...int) int {
+	scope := c.current()
+	offset := len(scope.code.Instructions)
+	scope.code.Instructions = append(scope.code.Instructions, Make(op, operands...)...)
+	if !isPure(op) {
+		scope.step = -1
+	}
+	return offset
+}
+
+// isPure reports whether an instruction cannot fail and has no effect but on
+// the stack, so the steps after it can be counted before it without changing
+// how the program runs, even when it stops at one of them.
+func isPure(op Opcode) bool {
+	switch op {
+	case OpConstant, OpNull, OpTrue, OpFalse, OpClosure, OpPop, OpUnset:
+		return true
+	default:
+		return false
+	}
+}
+
+// emitAt appends an instruction whose errors are located at tok.
+func (c *compiler) emitAt(tok token.Token, op Opcode, operands ...int) int {
+	c.locate(tok)
+	return c.emit(op, operands...)
+}
+
+// emitCount appends an instruction whose operand is a count, e.g. of
+// arguments. Its compile error is located at tok.
+func (c *compiler) emitCount(tok token.Token, op Opcode, count int) error {
+	if uint64(count) > math.MaxUint32 {
+		return newError(tok, "too many operands for %s: %d, the maximum is %d", definitions[op].Name, count, uint64(math.MaxUint32))
+	}
+	c.emit(op, count)
+	return nil
+}
+
+// access is what an instruction does with a variable.
+type access int
+
+const (
+	get access = iota
+	define
+	assign
+)
+
+var (
+	localOpcodes  = [...]Opcode{get: OpGetLocal, define: OpDefineLocal, assign: OpAssignLocal}
+	globalOpcodes = [...]Opcode{get: OpGetGlobal, define: OpDefineGlobal, assign: OpAssignGlobal}
+	nameOpcodes   = [...]Opcode{get: OpGetName, assign: OpAssignName}
+)
+
+// emitVariable appends an instruction accessing the variable with the given
+// name, located at tok. The variables of the current function are in slots
+// of its environment. The variables of enclosing functions are looked up by
+// name, as each call of a function has its own. The others are global
+// variables, in slots looked up when the program starts running.
+//
+// A function declares its variables in its own environment, so they are
+// never declared by name.
+func (c *compiler) emitVariable(tok token.Token, access access, name string) error {
+	if slot, ok := c.current().code.Locals[name]; ok {
+		c.emitAt(tok, localOpcodes[access], slot)
+		return nil
+	}
+
+	if access != define && c.isEnclosingLocal(name) {
+		index, err := c.addName(tok, name)
+		if err != nil {
+			return err
+		}
+		c.emitAt(tok, nameOpcodes[access], index)
+		return nil
+	}
+
+	index, err := c.addGlobal(tok, name)
+	if err != nil {
+		return err
+	}
+	c.emitAt(tok, globalOpcodes[access], index)
+	return nil
+}
+
+// isEnclosingLocal reports whether a name is a variable of a function
+// enclosing the current one.
+func (c *compiler) isEnclosingLocal(name string) bool {
+	for i := len(c.scopes) - 2; i > 0; i-- {
+		if _, ok := c.scopes[i].code.Locals[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// addName adds a name to the Names of the current code, and returns its index.
+func (c *compiler) addName(tok token.Token, name string) (int, error) {
+	scope := c.current()
+	if index, ok := scope.names[name]; ok {
+		return index, nil
+	}
+
+	index := len(scope.code.Names)
+	if uint64(index) > math.MaxUint32 {
+		return 0, newError(tok, "too many variable names")
+	}
+	scope.code.Names = append(scope.code.Names, name)
+	scope.names[name] = index
+	return index, nil
+}
+
+// addGlobal adds a name to the Globals of the program, and returns its index.
+func (c *compiler) addGlobal(tok token.Token, name string) (int, error) {
+	if index, ok := c.globals[name]; ok {
+		return index, nil
+	}
+
+	program := c.scopes[0].code
+	index := len(program.Globals)
+	if uint64(index) > math.MaxUint32 {
+		return 0, newError(tok, "too many variable names")
+	}
+	program.Globals = append(program.Globals, name)
+	c.globals[name] = index
+	return index, nil
+}
+
+// emitConstant appends an instruction pushing the constant of a literal at tok.
+func (c *compiler) emitConstant(tok token.Token, obj object.Object) error {
+	index, err := c.addConstant(tok, obj)
+	if err != nil {
+		return err
+	}
+	c.emit(OpConstant, index)
+	return nil
+}
+
+// addConstant adds a constant of the node at tok to the current code, and
+// returns its index. Literals with the same value share their constant.
+func (c *compiler) addConstant(tok token.Token, obj object.Object) (int, error) {
+	scope := c.current()
+	key, literal := literalKey(obj)
+	if index, ok := scope.constants[key]; ok && literal {
+		return index, nil
+	}
+
+	index := len(scope.code.Constants)
+	if uint64(index) > math.MaxUint32 {
+		return 0, newError(tok, "too many constants")
+	}
+	scope.code.Constants = append(scope.code.Constants, obj)
+	if literal {
+		scope.constants[key] = index
+	}
+	return index, nil
+}
+
+// literalKey returns the key of a literal constant. It reports false for
+// other constants, e.g. functions, which are never shared.
+func literalKey(obj object.Object) (constantKey, bool) {
+	switch obj := obj.(type) {
+	case *object.Integer, *object.String:
+		return constantKey{obj.Type(), obj.Inspect()}, true
+	case *object.Float:
+		// The bits tell apart the floats that print the same
+		return constantKey{obj.Type(), strconv.FormatUint(math.Float64bits(obj.Value), 16)}, true
+	}
+	return constantKey{}, false
+}
+
+// patchJump sets the target of the jump at offset to the next instruction.
+// Its errors are located at tok, the statement that jumps.
+func (c *compiler) patchJump(tok token.Token, offset int) error {
+	code := c.current().code
+	target := c.label()
+	if uint64(target) > math.MaxUint32 {
+		return newError(tok, "too much code to jump over")
+	}
+
+	op := Opcode(code.Instructions[offset])
+	copy(code.Instructions[offset:], Make(op, target))
+	return nil
+}
+
+// nodeToken returns a token spanning a node, to locate errors.
+func nodeToken(node ast.Node) token.Token {
+	if node == nil {
+		return token.Token{}
+	}
+	return token.Token{Literal: node.TokenLiteral(), Pos: node.Pos(), End: node.End()}
+}
@@ -0,0 +1,170 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/lexer"
+	"github.com/AlyxPink/meowlang/parser"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		op       Opcode
+		operands []int
+		expected []byte
+	}{
+		{OpConstant, []int{65534}, []byte{byte(OpConstant), 0, 0, 255, 254}},
+		{OpJump, []int{1 << 24}, []byte{byte(OpJump), 1, 0, 0, 0}},
+		{OpCall, []int{3}, []byte{byte(OpCall), 0, 0, 0, 3}},
+		{OpAdd, []int{}, []byte{byte(OpAdd)}},
+	}
+
+	for _, tt := range tests {
+		instruction := Make(tt.op, tt.operands...)
+		if string(instruction) != string(tt.expected) {
+			t.Errorf("%s: expected %v, got %v", definitions[tt.op].Name, tt.expected, instruction)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  string
+		constants int
+	}{
+		{
+			`lick x = 1 + 2`,
+			"0000 OpStep 5\n0005 OpConstant 0\n0010 OpConstant 1\n0015 OpAdd\n0016 OpDefineGlobal 0\n0021 OpPop\n",
+			2,
+		},
+		{
+			`hiss (x) { purr x } growl { }`,
+			"0000 OpStep 3\n0005 OpGetGlobal 0\n0010 OpJumpNotTruthy 32\n0015 OpStep 2\n0020 OpGetGlobal 0\n0025 OpPrint\n0026 OpPop\n" +
+				"0027 OpJump 33\n0032 OpUnset\n",
+			0,
+		},
+		{
+			`scratch (x) { nap(1) }`,
+			"0000 OpStep 2\n0005 OpJump 22\n0010 OpStep 2\n0015 OpConstant 0\n0020 OpNap\n0021 OpPop\n" +
+				"0022 OpStep 1\n0027 OpGetGlobal 0\n0032 OpJumpTruthy 10\n0037 OpNull\n0038 OpPop\n",
+			1,
+		},
+		{
+			`meow f(a) { a } f(1)`,
+			"0000 OpStep 2\n0005 OpClosure 0\n0010 OpDefineGlobal 0\n0015 OpPop\n" +
+				"0016 OpStep 3\n0021 OpGetGlobal 0\n0026 OpStep 1\n0031 OpConstant 1\n0036 OpCall 1\n0041 OpPop\n",
+			2,
+		},
+		{
+			`purr 1 purr "1" purr 1 purr 1.0 purr "1"`,
+			"0000 OpStep 3\n0005 OpConstant 0\n0010 OpPrint\n0011 OpPop\n0012 OpStep 2\n0017 OpConstant 1\n0022 OpPrint\n0023 OpPop\n" +
+				"0024 OpStep 2\n0029 OpConstant 0\n0034 OpPrint\n0035 OpPop\n0036 OpStep 2\n0041 OpConstant 2\n0046 OpPrint\n0047 OpPop\n" +
+				"0048 OpStep 2\n0053 OpConstant 1\n0058 OpPrint\n0059 OpPop\n",
+			3,
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.input)
+		p := parser.NewParser(l.Tokenize())
+		program := p.ParseProgram()
+
+		code, err := Compile(program)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", tt.input, err)
+		}
+		if code.Instructions.String() != tt.expected {
+			t.Errorf("%q: expected\n%s\ngot\n%s", tt.input, tt.expected, code.Instructions)
+		}
+		if len(code.Constants) != tt.constants {
+			t.Errorf("%q: expected %d constants, got %d", tt.input, tt.constants, len(code.Constants))
+		}
+	}
+}
+
+func TestCompile_Function(t *testing.T) {
+	l := lexer.NewLexer(`meow add(a, b) { claw a + b }`)
+	p := parser.NewParser(l.Tokenize())
+	program := p.ParseProgram()
+
+	code, err := Compile(program)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	fn, ok := code.Constants[0].(*Function)
+	if !ok {
+		t.Fatalf("expected a function constant, got %T", code.Constants[0])
+	}
+
+	expected := "0000 OpStep 4\n0005 OpGetLocal 0\n0010 OpStep 1\n0015 OpGetLocal 1\n0020 OpAdd\n0021 OpReturn\n0022 OpLast\n0023 OpReturn\n"
+	if fn.Code.Instructions.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, fn.Code.Instructions)
+	}
+	if fn.Inspect() != "meow(a, b) { ... }" {
+		t.Errorf("expected %q, got %q", "meow(a, b) { ... }", fn.Inspect())
+	}
+	if tok := fn.Code.Token(20); tok.Literal != "+" {
+		t.Errorf("expected OpAdd to be located at '+', got %q", tok.Literal)
+	}
+}
+
+func TestCompile_Variables(t *testing.T) {
+	l := lexer.NewLexer(`lick n = 0 meow counter(step) { lick count = n meow next() { count = count + step claw count } claw next }`)
+	p := parser.NewParser(l.Tokenize())
+	program := p.ParseProgram()
+
+	code, err := Compile(program)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if fmt.Sprint(code.Globals) != "[n counter]" {
+		t.Errorf("expected globals [n counter], got %v", code.Globals)
+	}
+
+	counter := code.Constants[1].(*Function)
+	if fmt.Sprint(counter.Code.LocalNames) != "[step count next]" {
+		t.Errorf("expected locals [step count next], got %v", counter.Code.LocalNames)
+	}
+	expected := "0000 OpStep 3\n0005 OpGetGlobal 0\n0010 OpDefineLocal 1\n0015 OpPop\n" +
+		"0016 OpStep 1\n0021 OpClosure 0\n0026 OpDefineLocal 2\n0031 OpPop\n" +
+		"0032 OpStep 2\n0037 OpGetLocal 2\n0042 OpReturn\n0043 OpLast\n0044 OpReturn\n"
+	if counter.Code.Instructions.String() != expected {
+		t.Errorf("expected counter\n%s\ngot\n%s", expected, counter.Code.Instructions)
+	}
+
+	next := counter.Code.Constants[0].(*Function)
+	if len(next.Code.LocalNames) != 0 {
+		t.Errorf("expected no locals in next, got %v", next.Code.LocalNames)
+	}
+	expected = "0000 OpStep 4\n0005 OpGetName 0\n0010 OpStep 1\n0015 OpGetName 1\n0020 OpAdd\n0021 OpAssignName 0\n0026 OpPop\n" +
+		"0027 OpStep 2\n0032 OpGetName 0\n0037 OpReturn\n0038 OpLast\n0039 OpReturn\n"
+	if next.Code.Instructions.String() != expected {
+		t.Errorf("expected next\n%s\ngot\n%s", expected, next.Code.Instructions)
+	}
+}
+
+// unknownStatement is a statement the compiler does not know about.
+type unknownStatement struct {
+	*ast.PrintStatement
+}
+
+func TestCompile_ErrorsAreLocated(t *testing.T) {
+	l := lexer.NewLexer("purr 1\n  purr 2")
+	p := parser.NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	program.Statements[1] = unknownStatement{program.Statements[1].(*ast.PrintStatement)}
+
+	_, err := Compile(program)
+
+	var compileErr *Error
+	if !errors.As(err, &compileErr) {
+		t.Fatalf("expected *Error, got %T (%v)", err, err)
+	}
+	if err.Error() != "2:3: cannot compile compiler.unknownStatement" {
+		t.Errorf("expected error %q, got %q", "2:3: cannot compile compiler.unknownStatement", err.Error())
+	}
+}
@@ -0,0 +1,245 @@
+package compiler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Instructions is a sequence of encoded instructions: an Opcode followed by its operands.
+type Instructions []byte
+
+// Opcode is the first byte of an instruction, telling the vm what to do.
+type Opcode byte
+
+const (
+	OpConstant Opcode = iota // push the constant at the operand index
+	OpNull                   // push null
+	OpTrue                   // push true
+	OpFalse                  // push false
+	OpPop                    // pop the value of a statement, which becomes the last value
+	OpUnset                  // forget the last value, for an empty block which has none
+
+	OpStep // count the evaluation of the operand number of nodes, see Bytecode.StepsAt
+
+	OpGetGlobal    // push the global variable at the operand index in Bytecode.Globals
+	OpDefineGlobal // declare the global variable at the operand index, with the value on top of the stack
+	OpAssignGlobal // update the global variable at the operand index, with the value on top of the stack
+	OpGetLocal     // push the variable of the function in the operand slot
+	OpDefineLocal  // declare the variable of the function in the operand slot, with the value on top of the stack
+	OpAssignLocal  // update the variable of the function in the operand slot, with the value on top of the stack
+	OpGetName      // push the variable named by the operand index, a variable of an enclosing function
+	OpAssignName   // update the variable named by the operand index, with the value on top of the stack
+
+	OpAdd          // +
+	OpSub          // -
+	OpMul          // *
+	OpDiv          // /
+	OpEqual        // ==
+	OpNotEqual     // !=
+	OpLess         // <
+	OpGreater      // >
+	OpLessEqual    // <=
+	OpGreaterEqual // >=
+	OpMinus        // prefix -
+	OpNot          // prefix !
+
+	OpJump          // jump to the operand offset
+	OpJumpNotTruthy // pop a condition, and jump to the operand offset if it is falsy
+	OpJumpTruthy    // pop a condition, and jump to the operand offset if it is truthy
+
+	OpArray       // pop the operand number of elements and push an array of them
+	OpHashable    // check that the value on top of the stack can be a map key
+	OpMap         // pop the operand number of key-value pairs and push a map of them
+	OpIndex       // pop a collection and an index, and push the element
+	OpSetIndex    // pop a collection, an index and a value, assign the element and push the value
+	OpDelete      // pop a collection and an index, remove the element and push null
+	OpInterpolate // pop the operand number of values and push the string of their printed forms
+
+	OpClosure // push a function of the constant at the operand index, capturing the current environment
+	OpCall    // call the function under the operand number of arguments, and push its result
+	OpReturn  // return the value on top of the stack from the current function
+	OpLast    // push the last value, the value of the last statement run, or null
+
+	OpPrint // pop a value, print it and push null
+	OpNap   // pop a duration, nap and push null
+)
+
+// Definition describes an Opcode: its name, for disassembly, and the width
+// in bytes of each of its operands.
+type Definition struct {
+	Name          string
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant: {"OpConstant", []int{4}},
+	OpNull:     {"OpNull", []int{}},
+	OpTrue:     {"OpTrue", []int{}},
+	OpFalse:    {"OpFalse", []int{}},
+	OpPop:      {"OpPop", []int{}},
+	OpUnset:    {"OpUnset", []int{}},
+
+	OpStep: {"OpStep", []int{4}},
+
+	OpGetGlobal:    {"OpGetGlobal", []int{4}},
+	OpDefineGlobal: {"OpDefineGlobal", []int{4}},
+	OpAssignGlobal: {"OpAssignGlobal", []int{4}},
+	OpGetLocal:     {"OpGetLocal", []int{4}},
+	OpDefineLocal:  {"OpDefineLocal", []int{4}},
+	OpAssignLocal:  {"OpAssignLocal", []int{4}},
+	OpGetName:      {"OpGetName", []int{4}},
+	OpAssignName:   {"OpAssignName", []int{4}},
+
+	OpAdd:          {"OpAdd", []int{}},
+	OpSub:          {"OpSub", []int{}},
+	OpMul:          {"OpMul", []int{}},
+	OpDiv:          {"OpDiv", []int{}},
+	OpEqual:        {"OpEqual", []int{}},
+	OpNotEqual:     {"OpNotEqual", []int{}},
+	OpLess:         {"OpLess", []int{}},
+	OpGreater:      {"OpGreater", []int{}},
+	OpLessEqual:    {"OpLessEqual", []int{}},
+	OpGreaterEqual: {"OpGreaterEqual", []int{}},
+	OpMinus:        {"OpMinus", []int{}},
+	OpNot:          {"OpNot", []int{}},
+
+	OpJump:          {"OpJump", []int{4}},
+	OpJumpNotTruthy: {"OpJumpNotTruthy", []int{4}},
+	OpJumpTruthy:    {"OpJumpTruthy", []int{4}},
+
+	OpArray:       {"OpArray", []int{4}},
+	OpHashable:    {"OpHashable", []int{}},
+	OpMap:         {"OpMap", []int{4}},
+	OpIndex:       {"OpIndex", []int{}},
+	OpSetIndex:    {"OpSetIndex", []int{}},
+	OpDelete:      {"OpDelete", []int{}},
+	OpInterpolate: {"OpInterpolate", []int{4}},
+
+	OpClosure: {"OpClosure", []int{4}},
+	OpCall:    {"OpCall", []int{4}},
+	OpReturn:  {"OpReturn", []int{}},
+	OpLast:    {"OpLast", []int{}},
+
+	OpPrint: {"OpPrint", []int{}},
+	OpNap:   {"OpNap", []int{}},
+}
+
+// Lookup returns the definition of an opcode.
+func Lookup(op Opcode) (*Definition, error) {
+	def, ok := definitions[op]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+	return def, nil
+}
+
+// Operators holds, for the opcodes of infix and prefix operators, the operator they apply.
+var Operators = [...]string{
+	OpAdd:          "+",
+	OpSub:          "-",
+	OpMul:          "*",
+	OpDiv:          "/",
+	OpEqual:        "==",
+	OpNotEqual:     "!=",
+	OpLess:         "<",
+	OpGreater:      ">",
+	OpLessEqual:    "<=",
+	OpGreaterEqual: ">=",
+	OpMinus:        "-",
+	OpNot:          "!",
+}
+
+// Make encodes an instruction. Operands are big-endian.
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	length := 1
+	for _, width := range def.OperandWidths {
+		length += width
+	}
+
+	instruction := make([]byte, length)
+	instruction[0] = byte(op)
+
+	offset := 1
+	for i, operand := range operands {
+		width := def.OperandWidths[i]
+		switch width {
+		case 4:
+			binary.BigEndian.PutUint32(instruction[offset:], uint32(operand))
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(operand))
+		case 1:
+			instruction[offset] = byte(operand)
+		}
+		offset += width
+	}
+
+	return instruction
+}
+
+// ReadOperands decodes the operands of an instruction, and returns the number of bytes they take.
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+
+	for i, width := range def.OperandWidths {
+		switch width {
+		case 4:
+			operands[i] = int(ReadUint32(ins[offset:]))
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[i] = int(ins[offset])
+		}
+		offset += width
+	}
+
+	return operands, offset
+}
+
+// ReadUint16 decodes a two-byte operand.
+func ReadUint16(ins Instructions) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
+
+// ReadUint32 decodes a four-byte operand.
+func ReadUint32(ins Instructions) uint32 {
+	return binary.BigEndian.Uint32(ins)
+}
+
+// String disassembles the instructions, one per line prefixed by its offset,
+// e.g. "0003 OpConstant 1".
+func (ins Instructions) String() string {
+	var out bytes.Buffer
+
+	for i := 0; i < len(ins); {
+		def, err := Lookup(Opcode(ins[i]))
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			i++
+			continue
+		}
+
+		operands, read := ReadOperands(def, ins[i+1:])
+		fmt.Fprintf(&out, "%04d %s\n", i, formatInstruction(def, operands))
+		i += 1 + read
+	}
+
+	return out.String()
+}
+
+func formatInstruction(def *Definition, operands []int) string {
+	switch len(operands) {
+	case 0:
+		return def.Name
+	case 1:
+		return fmt.Sprintf("%s %d", def.Name, operands[0])
+	default:
+		return fmt.Sprintf("ERROR: unhandled operand count for %s", def.Name)
+	}
+}
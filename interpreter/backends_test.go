@@ -0,0 +1,93 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/interpreter"
+	"github.com/AlyxPink/meowlang/lexer"
+	"github.com/AlyxPink/meowlang/object"
+	"github.com/AlyxPink/meowlang/parser"
+	"github.com/AlyxPink/meowlang/vm"
+)
+
+// backend is a way to run programs. Every test runs on all of them, to check
+// that they behave the same.
+type backend struct {
+	name string
+	new  func(stdout, stderr io.Writer) interpreter.Engine
+}
+
+var backends = []backend{
+	{"interpreter", func(stdout, stderr io.Writer) interpreter.Engine {
+		return interpreter.NewInterpreterWithWriters(stdout, stderr)
+	}},
+	{"vm", func(stdout, stderr io.Writer) interpreter.Engine {
+		return vm.NewWithWriters(stdout, stderr)
+	}},
+}
+
+// forEachBackend runs test as a subtest for each backend.
+func forEachBackend(t *testing.T, test func(t *testing.T, b backend)) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			test(t, b)
+		})
+	}
+}
+
+func interpret(t *testing.T, input string) string {
+	t.Helper()
+	output, _ := evaluate(t, input)
+	return output
+}
+
+// evaluate runs a program on each backend and returns both its output and
+// its result. It fails the test if the backends disagree.
+func evaluate(t *testing.T, input string) (string, object.Object) {
+	t.Helper()
+	program := parse(input)
+
+	var output string
+	var result object.Object
+	for index, b := range backends {
+		var out bytes.Buffer
+		got := b.new(&out, &out).Interpret(program)
+
+		if index == 0 {
+			output, result = out.String(), got
+			continue
+		}
+		if out.String() != output || describe(got) != describe(result) {
+			t.Errorf("%q: the %s prints %q and returns %s, the %s prints %q and returns %s", input,
+				b.name, out.String(), describe(got), backends[0].name, output, describe(result))
+		}
+	}
+
+	return output, result
+}
+
+func parse(input string) *ast.Program {
+	l := lexer.NewLexer(input)
+	p := parser.NewParser(l.Tokenize())
+	return p.ParseProgram()
+}
+
+// describe describes the result of a program, to compare backends.
+func describe(obj object.Object) string {
+	switch obj := obj.(type) {
+	case nil:
+		return "nothing"
+	case *object.Error:
+		return "error " + obj.Error()
+	default:
+		return fmt.Sprintf("%s %s", obj.Type(), obj.Inspect())
+	}
+}
+
+func isError(obj object.Object) bool {
+	return obj != nil && obj.Type() == object.ERROR_OBJ
+}
@@ -0,0 +1,22 @@
+package interpreter
+
+import "github.com/AlyxPink/meowlang/object"
+
+// frame is a function call in progress.
+type frame struct {
+	callerEnv *object.Environment // the environment to go back to when the call returns
+}
+
+// pushFrame enters a function call, evaluating the next statements in env.
+func (i *Interpreter) pushFrame(env *object.Environment) {
+	i.frames = append(i.frames, frame{callerEnv: i.env})
+	i.env = env
+}
+
+// popFrame leaves the current function call, going back to the environment of the caller.
+func (i *Interpreter) popFrame() {
+	top := len(i.frames) - 1
+	i.env = i.frames[top].callerEnv
+	i.frames[top] = frame{}
+	i.frames = i.frames[:top]
+}
@@ -0,0 +1,31 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/AlyxPink/meowlang/lexer"
+	"github.com/AlyxPink/meowlang/parser"
+)
+
+func TestInterpreter_FramesUnwind(t *testing.T) {
+	tests := []string{
+		`meow f(x) { hiss (x > 0) { f(x - 1) } claw x } f(2)`,
+		`meow f(x) { hiss (x > 0) { f(x - 1) } claw missing } f(2)`,
+	}
+
+	for _, input := range tests {
+		l := lexer.NewLexer(input)
+		p := parser.NewParser(l.Tokenize())
+		program := p.ParseProgram()
+
+		interpreter := NewInterpreter()
+		interpreter.Interpret(program)
+
+		if len(interpreter.frames) != 0 {
+			t.Errorf("%q: expected no call in progress, got %d", input, len(interpreter.frames))
+		}
+		if interpreter.env != interpreter.Env() {
+			t.Errorf("%q: expected to be back in the global environment", input)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package interpreter
+
+import "math"
+
+// The int64 arithmetic below reports whether the result fits in an int64, so
+// that small integers stay fast and only overflowing results promote to big.Int.
+
+// addInt64 returns a + b, and whether it did not overflow.
+func addInt64(a, b int64) (int64, bool) {
+	c := a + b
+	// Overflow happened if both operands have the same sign, but not the result
+	return c, (a^c)&(b^c) >= 0
+}
+
+// subInt64 returns a - b, and whether it did not overflow.
+func subInt64(a, b int64) (int64, bool) {
+	c := a - b
+	// Overflow happened if the operands have different signs, and the result has the sign of b
+	return c, (a^b)&(a^c) >= 0
+}
+
+// mulInt64 returns a * b, and whether it did not overflow.
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	if (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	c := a * b
+	return c, c/b == a
+}
+
+// quoInt64 returns a / b truncated toward zero, and whether it did not overflow.
+// b must not be zero.
+func quoInt64(a, b int64) (int64, bool) {
+	if a == math.MinInt64 && b == -1 {
+		return 0, false
+	}
+	return a / b, true
+}
+
+// negInt64 returns -a, and whether it did not overflow.
+func negInt64(a int64) (int64, bool) {
+	if a == math.MinInt64 {
+		return 0, false
+	}
+	return -a, true
+}
@@ -0,0 +1,68 @@
+package interpreter
+
+import (
+	"math"
+	"testing"
+
+	"github.com/AlyxPink/meowlang/lexer"
+	"github.com/AlyxPink/meowlang/parser"
+)
+
+func TestCheckedInt64Arithmetic(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       func(a, b int64) (int64, bool)
+		a, b     int64
+		expected int64
+		ok       bool
+	}{
+		{"add", addInt64, 1, 2, 3, true},
+		{"add", addInt64, math.MaxInt64, 1, 0, false},
+		{"add", addInt64, math.MinInt64, -1, 0, false},
+		{"add", addInt64, math.MaxInt64, math.MinInt64, -1, true},
+		{"sub", subInt64, 1, 2, -1, true},
+		{"sub", subInt64, math.MinInt64, 1, 0, false},
+		{"sub", subInt64, math.MaxInt64, -1, 0, false},
+		{"sub", subInt64, -1, math.MinInt64, math.MaxInt64, true},
+		{"mul", mulInt64, 6, 7, 42, true},
+		{"mul", mulInt64, 0, math.MinInt64, 0, true},
+		{"mul", mulInt64, math.MaxInt64, 2, 0, false},
+		{"mul", mulInt64, math.MinInt64, -1, 0, false},
+		{"mul", mulInt64, -1, math.MinInt64, 0, false},
+		{"mul", mulInt64, math.MinInt64, 1, math.MinInt64, true},
+		{"quo", quoInt64, 7, 2, 3, true},
+		{"quo", quoInt64, -7, 2, -3, true},
+		{"quo", quoInt64, math.MinInt64, -1, 0, false},
+	}
+
+	for _, tt := range tests {
+		value, ok := tt.op(tt.a, tt.b)
+		if ok != tt.ok || (ok && value != tt.expected) {
+			t.Errorf("%s(%d, %d): expected (%d, %t), got (%d, %t)", tt.name, tt.a, tt.b, tt.expected, tt.ok, value, ok)
+		}
+	}
+
+	if _, ok := negInt64(math.MinInt64); ok {
+		t.Errorf("negInt64(%d) should overflow", int64(math.MinInt64))
+	}
+	if value, ok := negInt64(5); !ok || value != -5 {
+		t.Errorf("negInt64(5): expected (-5, true), got (%d, %t)", value, ok)
+	}
+}
+
+func BenchmarkSmallIntegerArithmetic(b *testing.B) {
+	input := `
+    lick i = 0
+    lick total = 0
+    scratch (i < 1000) {
+        total = total + i * 2 - i / 3
+        i = i + 1
+    }`
+
+	l := lexer.NewLexer(input)
+	program := parser.NewParser(l.Tokenize()).ParseProgram()
+
+	for n := 0; n < b.N; n++ {
+		NewInterpreter().Interpret(program)
+	}
+}
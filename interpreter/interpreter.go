@@ -2,57 +2,154 @@ package interpreter
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/AlyxPink/meowlang/ast"
 	"github.com/AlyxPink/meowlang/object"
+	"github.com/AlyxPink/meowlang/token"
 )
 
+var (
+	TRUE  = &object.Boolean{Value: true}
+	FALSE = &object.Boolean{Value: false}
+)
+
+// Engine runs programs: the tree-walking Interpreter, or the VM of the vm
+// package which runs them compiled to bytecode. Both behave the same.
+type Engine interface {
+	Interpret(node ast.Node) object.Object
+	InterpretContext(ctx context.Context, node ast.Node) object.Object
+	Call(fn object.Object, args ...object.Object) object.Object
+	CallContext(ctx context.Context, fn object.Object, args ...object.Object) object.Object
+	Env() *object.Environment
+	SetInput(in io.Reader)
+	SetSleeper(sleeper Sleeper)
+	SetNapUnit(unit time.Duration)
+	SetLimits(limits Limits)
+}
+
 // Interpreter represents the interpreter for the MeowLang programming language.
+// Function bodies are evaluated by the same Interpreter as their caller, so
+// they share its output, input and settings.
 type Interpreter struct {
-	env *object.Environment
-	out *bytes.Buffer
+	rt     *Runtime            // the globals, input, output and limits of the program
+	env    *object.Environment // the environment of the code being evaluated
+	frames []frame             // the function calls in progress, the innermost last
 }
 
-// NewInterpreter creates a new instance of Interpreter.
+// NewInterpreter creates a new instance of Interpreter. What the program prints is discarded.
 func NewInterpreter() *Interpreter {
-	return &Interpreter{env: object.NewEnvironment(), out: new(bytes.Buffer)}
+	return NewInterpreterWithOutput(io.Discard)
 }
 
-// NewInterpreterWithOutput creates a new instance of Interpreter with a specified output buffer.
-func NewInterpreterWithOutput(out *bytes.Buffer) *Interpreter {
-	env := object.NewEnvironment()
-	return &Interpreter{env: env, out: out}
+// NewInterpreterWithOutput creates a new instance of Interpreter printing to out,
+// as the program runs. Diagnostics are written to out too.
+func NewInterpreterWithOutput(out io.Writer) *Interpreter {
+	return NewInterpreterWithWriters(out, out)
+}
+
+// NewInterpreterWithWriters creates a new instance of Interpreter printing to
+// stdout, as the program runs, and writing diagnostics to stderr.
+func NewInterpreterWithWriters(stdout, stderr io.Writer) *Interpreter {
+	return NewInterpreterWithRuntime(NewRuntime(stdout, stderr))
 }
 
 // NewInterpreterWithEnv creates a new instance of Interpreter with a specified environment.
+// The environment must enclose one created by NewInterpreter for built-in functions to be available.
 func NewInterpreterWithEnv(env *object.Environment) *Interpreter {
-	return &Interpreter{env: env, out: new(bytes.Buffer)}
+	return NewInterpreterWithRuntime(NewRuntimeWithEnv(env))
+}
+
+// NewInterpreterWithRuntime creates a new instance of Interpreter running programs on rt.
+func NewInterpreterWithRuntime(rt *Runtime) *Interpreter {
+	return &Interpreter{rt: rt, env: rt.Env()}
+}
+
+// SetInput sets where the 'input' built-in function reads from, os.Stdin by default.
+func (i *Interpreter) SetInput(in io.Reader) {
+	i.rt.SetInput(in)
+}
+
+// Env returns the environment the program runs in, e.g. to define variables before running it.
+func (i *Interpreter) Env() *object.Environment {
+	return i.rt.Env()
+}
+
+// SetSleeper replaces the Sleeper used by 'nap', so tests can run without actually sleeping.
+func (i *Interpreter) SetSleeper(sleeper Sleeper) {
+	i.rt.SetSleeper(sleeper)
+}
+
+// SetNapUnit sets the duration of one unit of time passed to 'nap', e.g. time.Millisecond.
+func (i *Interpreter) SetNapUnit(unit time.Duration) {
+	i.rt.SetNapUnit(unit)
+}
+
+// SetLimits sets the resources the program may use. Only the call depth is limited by default.
+func (i *Interpreter) SetLimits(limits Limits) {
+	i.rt.SetLimits(limits)
 }
 
 // Interpret interprets the given AST node and returns the resulting object.
 func (i *Interpreter) Interpret(node ast.Node) object.Object {
+	if err := i.step(node); err != nil {
+		return err
+	}
+
 	switch node := node.(type) {
 	case *ast.Program:
 		return i.evalProgram(node)
 	case *ast.AssignStatement:
 		return i.evalAssignStatement(node)
+	case *ast.ReassignStatement:
+		return i.evalReassignStatement(node)
+	case *ast.IndexAssignStatement:
+		return i.evalIndexAssignStatement(node)
+	case *ast.DeleteStatement:
+		return i.evalDeleteStatement(node)
 	case *ast.FunctionStatement:
 		return i.evalFunctionStatement(node)
 	case *ast.ReturnStatement:
 		return i.evalReturnStatement(node)
 	case *ast.PrintStatement:
 		return i.evalPrintStatement(node)
+	case *ast.ExpressionStatement:
+		return i.Interpret(node.Expression)
 	case *ast.BlockStatement:
 		return i.evalBlockStatement(node)
+	case *ast.IfStatement:
+		return i.evalIfStatement(node)
+	case *ast.WhileStatement:
+		return i.evalWhileStatement(node)
+	case *ast.NapStatement:
+		return i.evalNapStatement(node)
 	case *ast.CallExpression:
 		return i.evalCallExpression(node)
 	case *ast.Identifier:
 		return i.evalIdentifier(node)
 	case *ast.IntegerLiteral:
-		return &object.Integer{Value: node.Value}
+		return &object.Integer{Value: node.Value, Big: node.Big}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
+	case *ast.InterpolatedString:
+		return i.evalInterpolatedString(node)
+	case *ast.ArrayLiteral:
+		return i.evalArrayLiteral(node)
+	case *ast.MapLiteral:
+		return i.evalMapLiteral(node)
+	case *ast.FunctionLiteral:
+		return i.newFunction(node.Parameters, node.Body)
+	case *ast.IndexExpression:
+		return i.evalIndexExpression(node)
+	case *ast.BooleanLiteral:
+		return nativeBoolToBooleanObject(node.Value)
+	case *ast.PrefixExpression:
+		return i.evalPrefixExpression(node)
 	case *ast.InfixExpression:
 		return i.evalInfixExpression(node)
 	}
@@ -64,6 +161,13 @@ func (i *Interpreter) evalProgram(program *ast.Program) object.Object {
 	var result object.Object
 	for _, stmt := range program.Statements {
 		result = i.Interpret(stmt)
+
+		switch result := result.(type) {
+		case *object.ReturnValue:
+			return newError(result.Token, "claw can only be used inside a function")
+		case *object.Error:
+			return result
+		}
 	}
 	return result
 }
@@ -71,43 +175,105 @@ func (i *Interpreter) evalProgram(program *ast.Program) object.Object {
 // evalAssignStatement evaluates an assignment statement.
 func (i *Interpreter) evalAssignStatement(stmt *ast.AssignStatement) object.Object {
 	val := i.Interpret(stmt.Value)
+	if isError(val) {
+		return val
+	}
 	if val != nil {
 		i.env.Set(stmt.Name.Value, val)
 	}
 	return val
 }
 
+// evalReassignStatement evaluates an assignment to an existing variable,
+// updating the binding where it was declared instead of shadowing it.
+func (i *Interpreter) evalReassignStatement(stmt *ast.ReassignStatement) object.Object {
+	val := i.Interpret(stmt.Value)
+	if isError(val) {
+		return val
+	}
+	if val == nil {
+		return &object.Null{}
+	}
+
+	if _, ok := i.env.Assign(stmt.Name.Value, val); !ok {
+		return newError(stmt.Name.Token, "cannot assign to undeclared variable %s, declare it with lick first", stmt.Name.Value)
+	}
+	return val
+}
+
+// evalIndexAssignStatement evaluates an assignment to an element, e.g. xs[0] = 1.
+func (i *Interpreter) evalIndexAssignStatement(stmt *ast.IndexAssignStatement) object.Object {
+	left := i.Interpret(stmt.Target.Left)
+	if isError(left) {
+		return left
+	}
+	index := i.Interpret(stmt.Target.Index)
+	if isError(index) {
+		return index
+	}
+	val := i.Interpret(stmt.Value)
+	if isError(val) {
+		return val
+	}
+	return SetIndex(stmt.Target.Token, left, index, val)
+}
+
+// evalDeleteStatement evaluates a 'shoo' statement, removing an element from a collection.
+func (i *Interpreter) evalDeleteStatement(stmt *ast.DeleteStatement) object.Object {
+	left := i.Interpret(stmt.Target.Left)
+	if isError(left) {
+		return left
+	}
+	index := i.Interpret(stmt.Target.Index)
+	if isError(index) {
+		return index
+	}
+	return Delete(stmt.Token, stmt.Target.Token, left, index)
+}
+
 // evalFunctionStatement evaluates a function definition statement.
 func (i *Interpreter) evalFunctionStatement(stmt *ast.FunctionStatement) object.Object {
-	params := make([]*object.Identifier, len(stmt.Parameters))
-	for index, param := range stmt.Parameters {
+	function := i.newFunction(stmt.Parameters, stmt.Body)
+
+	i.env.Set(stmt.Name.Value, function)
+
+	return function
+}
+
+// newFunction creates a function. It captures the current environment, so its
+// body can use the variables in scope where it is defined, even after that scope ends.
+func (i *Interpreter) newFunction(parameters []*ast.Identifier, body *ast.BlockStatement) *object.Function {
+	params := make([]*object.Identifier, len(parameters))
+	for index, param := range parameters {
 		params[index] = &object.Identifier{Name: param.Value}
 	}
 
-	body := stmt.Body
-
-	function := &object.Function{
+	return &object.Function{
 		Parameters: params,
 		Body:       body,
 		Env:        i.env,
 	}
-
-	i.env.Set(stmt.Name.Value, function)
-
-	return function
 }
 
 // evalReturnStatement evaluates a return statement.
 func (i *Interpreter) evalReturnStatement(stmt *ast.ReturnStatement) object.Object {
 	val := i.Interpret(stmt.ReturnValue)
-	return val
+	if isError(val) {
+		return val
+	}
+	return &object.ReturnValue{Value: val, Token: stmt.Token}
 }
 
 // evalPrintStatement evaluates a print statement.
 func (i *Interpreter) evalPrintStatement(stmt *ast.PrintStatement) object.Object {
 	val := i.Interpret(stmt.Value)
+	if isError(val) {
+		return val
+	}
 	if val != nil {
-		fmt.Fprintln(i.out, val.Inspect())
+		if err := i.rt.Print(stmt.Token, val); err != nil {
+			return err
+		}
 	}
 	return &object.Null{}
 }
@@ -117,13 +283,77 @@ func (i *Interpreter) evalBlockStatement(block *ast.BlockStatement) object.Objec
 	var result object.Object
 	for _, stmt := range block.Statements {
 		result = i.Interpret(stmt)
+
+		// Stop at 'claw' or errors and let them unwind to the enclosing function or program
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+				return result
+			}
+		}
 	}
 	return result
 }
 
+// evalIfStatement evaluates a conditional statement, running the consequence
+// when the condition is truthy and the alternative otherwise.
+func (i *Interpreter) evalIfStatement(stmt *ast.IfStatement) object.Object {
+	condition := i.Interpret(stmt.Condition)
+	if isError(condition) {
+		return condition
+	}
+
+	if IsTruthy(condition) {
+		return i.evalBlockStatement(stmt.Consequence)
+	} else if stmt.Alternative != nil {
+		return i.evalBlockStatement(stmt.Alternative)
+	}
+
+	return &object.Null{}
+}
+
+// evalWhileStatement evaluates a 'scratch' loop, running the body until the
+// condition is no longer truthy.
+func (i *Interpreter) evalWhileStatement(stmt *ast.WhileStatement) object.Object {
+	for {
+		condition := i.Interpret(stmt.Condition)
+		if isError(condition) {
+			return condition
+		}
+		if !IsTruthy(condition) {
+			break
+		}
+
+		result := i.evalBlockStatement(stmt.Body)
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+				return result
+			}
+		}
+	}
+
+	return &object.Null{}
+}
+
+// evalNapStatement evaluates a 'nap' statement, pausing for the given number of nap units.
+func (i *Interpreter) evalNapStatement(stmt *ast.NapStatement) object.Object {
+	val := i.Interpret(stmt.Duration)
+	if isError(val) {
+		return val
+	}
+	if err := i.rt.Nap(stmt.Token, val); err != nil {
+		return err
+	}
+	return &object.Null{}
+}
+
 // evalCallExpression evaluates a function call expression.
 func (i *Interpreter) evalCallExpression(exp *ast.CallExpression) object.Object {
 	function := i.Interpret(exp.Function)
+	if isError(function) {
+		return function
+	}
 	if function == nil {
 		return &object.Null{}
 	}
@@ -131,16 +361,38 @@ func (i *Interpreter) evalCallExpression(exp *ast.CallExpression) object.Object
 	args := make([]object.Object, len(exp.Arguments))
 	for index, arg := range exp.Arguments {
 		args[index] = i.Interpret(arg)
+		if isError(args[index]) {
+			return args[index]
+		}
 	}
 
-	return i.applyFunction(function, args)
+	return i.applyFunction(exp.Token, function, args)
+}
+
+// Call calls a function or a built-in with the given arguments, e.g. a
+// function defined by a program that already ran. It returns an Error if fn
+// cannot be called with them.
+func (i *Interpreter) Call(fn object.Object, args ...object.Object) object.Object {
+	return i.applyFunction(token.Token{}, fn, args)
 }
 
 // applyFunction applies a function to its arguments.
-func (i *Interpreter) applyFunction(fn object.Object, args []object.Object) object.Object {
+func (i *Interpreter) applyFunction(tok token.Token, fn object.Object, args []object.Object) object.Object {
+	if builtin, ok := fn.(*object.Builtin); ok {
+		return i.rt.CallBuiltin(tok, builtin, args)
+	}
+
 	function, ok := fn.(*object.Function)
 	if !ok {
-		return &object.Null{}
+		return newError(tok, "not a function: %s", fn.Type())
+	}
+
+	if len(args) != len(function.Parameters) {
+		return newError(tok, "wrong number of arguments: want=%d, got=%d", len(function.Parameters), len(args))
+	}
+
+	if err := i.rt.CheckCallDepth(tok, len(i.frames)); err != nil {
+		return err
 	}
 
 	extendedEnv := object.NewEnclosedEnvironment(function.Env)
@@ -149,10 +401,22 @@ func (i *Interpreter) applyFunction(fn object.Object, args []object.Object) obje
 		extendedEnv.Set(param.Name, args[paramIdx])
 	}
 
-	evaluator := NewInterpreterWithEnv(extendedEnv)
-	result := evaluator.Interpret(function.Body)
+	i.pushFrame(extendedEnv)
+	result := i.Interpret(function.Body)
+	i.popFrame()
 
-	return result
+	return unwrapReturnValue(result)
+}
+
+// unwrapReturnValue unwraps the value of a 'claw' statement once it reaches the function call.
+func unwrapReturnValue(obj object.Object) object.Object {
+	if returnValue, ok := obj.(*object.ReturnValue); ok {
+		return returnValue.Value
+	}
+	if obj == nil {
+		return &object.Null{}
+	}
+	return obj
 }
 
 // evalIdentifier evaluates an identifier by looking it up in the environment.
@@ -160,52 +424,101 @@ func (i *Interpreter) evalIdentifier(node *ast.Identifier) object.Object {
 	if val, ok := i.env.Get(node.Value); ok {
 		return val
 	}
-	// If the identifier is not found, return an error or null
-	return &object.Null{}
+	return newError(node.Token, "identifier not found: %s", node.Value)
 }
 
-// evalInfixExpression evaluates an infix expression.
-func (i *Interpreter) evalInfixExpression(exp *ast.InfixExpression) object.Object {
-	left := i.Interpret(exp.Left)
-	right := i.Interpret(exp.Right)
+// evalInterpolatedString evaluates a string with embedded expressions,
+// rendering each part with Inspect.
+func (i *Interpreter) evalInterpolatedString(str *ast.InterpolatedString) object.Object {
+	var out bytes.Buffer
+
+	for _, part := range str.Parts {
+		val := i.Interpret(part)
+		if isError(val) {
+			return val
+		}
+		out.WriteString(val.Inspect())
+	}
 
-	switch {
-	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
-		return i.evalIntegerInfixExpression(exp.Operator, left, right)
-	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
-		return i.evalStringInfixExpression(exp.Operator, left, right)
+	return &object.String{Value: out.String()}
+}
+
+// evalArrayLiteral evaluates an array literal, e.g. [1, 2, 3].
+func (i *Interpreter) evalArrayLiteral(array *ast.ArrayLiteral) object.Object {
+	elements := make([]object.Object, len(array.Elements))
+	for index, element := range array.Elements {
+		elements[index] = i.Interpret(element)
+		if isError(elements[index]) {
+			return elements[index]
+		}
 	}
+	return &object.Array{Elements: elements}
+}
 
-	return &object.Null{}
+// evalMapLiteral evaluates a map literal, e.g. {"name": "Mochi", "age": 3}.
+func (i *Interpreter) evalMapLiteral(lit *ast.MapLiteral) object.Object {
+	m := object.NewMap()
+	for _, pair := range lit.Pairs {
+		key := i.Interpret(pair.Key)
+		if isError(key) {
+			return key
+		}
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError(nodeToken(pair.Key), "unusable as map key: %s", key.Type())
+		}
+
+		value := i.Interpret(pair.Value)
+		if isError(value) {
+			return value
+		}
+
+		m.Set(hashKey, value)
+	}
+	return m
 }
 
-// evalIntegerInfixExpression evaluates an infix expression with integer operands.
-func (i *Interpreter) evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
-	leftVal := left.(*object.Integer).Value
-	rightVal := right.(*object.Integer).Value
-
-	switch operator {
-	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
-	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
-	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
-	case "/":
-		return &object.Integer{Value: leftVal / rightVal}
-	default:
-		return &object.Null{}
+// evalIndexExpression evaluates an index expression, e.g. xs[0].
+func (i *Interpreter) evalIndexExpression(exp *ast.IndexExpression) object.Object {
+	left := i.Interpret(exp.Left)
+	if isError(left) {
+		return left
+	}
+	index := i.Interpret(exp.Index)
+	if isError(index) {
+		return index
+	}
+	return Index(exp.Token, left, index)
+}
+
+// evalPrefixExpression evaluates a prefix expression.
+func (i *Interpreter) evalPrefixExpression(exp *ast.PrefixExpression) object.Object {
+	right := i.Interpret(exp.Right)
+	if isError(right) {
+		return right
 	}
+	return Prefix(exp.Token, exp.Operator, right)
 }
 
-// evalStringInfixExpression evaluates an infix expression with string operands.
-func (i *Interpreter) evalStringInfixExpression(operator string, left, right object.Object) object.Object {
-	if operator != "+" {
-		return &object.Null{} // or handle as an error
+// evalInfixExpression evaluates an infix expression, see Infix.
+func (i *Interpreter) evalInfixExpression(exp *ast.InfixExpression) object.Object {
+	left := i.Interpret(exp.Left)
+	if isError(left) {
+		return left
 	}
+	right := i.Interpret(exp.Right)
+	if isError(right) {
+		return right
+	}
+	return Infix(exp.Token, exp.Operator, left, right)
+}
 
-	leftVal := left.(*object.String).Value
-	rightVal := right.(*object.String).Value
+// newError creates an Error object with a formatted message, located at the given token.
+func newError(tok token.Token, format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...), Token: tok}
+}
 
-	return &object.String{Value: leftVal + rightVal}
+// isError reports whether an object is an Error.
+func isError(obj object.Object) bool {
+	return obj != nil && obj.Type() == object.ERROR_OBJ
 }
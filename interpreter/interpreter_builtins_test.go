@@ -0,0 +1,131 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/AlyxPink/meowlang/object"
+)
+
+func TestInterpreter_Builtins(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{`purr len("")`, "0\n"},
+		{`purr len("meow")`, "4\n"},
+		{`purr len("🐱🐾")`, "2\n"},
+		{`purr len([1, 2, 3])`, "3\n"},
+		{`purr len({"a": 1})`, "1\n"},
+		{`purr type(1)`, "INTEGER\n"},
+		{`purr type(99999999999999999999)`, "INTEGER\n"},
+		{`purr type(1.5)`, "FLOAT\n"},
+		{`purr type("a")`, "STRING\n"},
+		{`purr type(true)`, "BOOLEAN\n"},
+		{`purr type([])`, "ARRAY\n"},
+		{`purr type({})`, "MAP\n"},
+		{`purr type(meow() { claw })`, "FUNCTION\n"},
+		{`purr type(meow() { claw }())`, "NULL\n"},
+		{`purr type(len)`, "BUILTIN\n"},
+		{`purr str(12) + str(3)`, "123\n"},
+		{`purr str([1, "a"])`, "[1, \"a\"]\n"},
+		{`purr str(2.0)`, "2.0\n"},
+		{`purr int("42") + 1`, "43\n"},
+		{`purr int(" -7 ")`, "-7\n"},
+		{`purr int("99999999999999999999")`, "99999999999999999999\n"},
+		{`purr int(3.9)`, "3\n"},
+		{`purr int(-3.9)`, "-3\n"},
+		{`purr int(1e20)`, "100000000000000000000\n"},
+		{`purr int(true) + int(false)`, "1\n"},
+		{`purr int(5)`, "5\n"},
+		{`purr float(7) / 2`, "3.5\n"},
+		{`purr float("2.5")`, "2.5\n"},
+		{`purr float("1e3")`, "1000.0\n"},
+		{`purr float(1.5)`, "1.5\n"},
+		{`lick xs = [1] push(xs, 2) purr xs`, "[1, 2]\n"},
+		{`purr push([], "a")`, "[\"a\"]\n"},
+		{`purr keys({"b": 1, "a": 2})`, "[\"b\", \"a\"]\n"},
+		{`purr keys({})`, "[]\n"},
+		{`purr len`, "builtin len\n"},
+		{`lick size = len purr size("abc")`, "3\n"},
+		{`meow apply(f, x) { claw f(x) } purr apply(str, 5) + "!"`, "5!\n"},
+		{`lick len = 3 purr len`, "3\n"},
+		{`meow shout(str) { claw str + "!" } purr shout("meow") + str(1)`, "meow!1\n"},
+	}
+
+	for _, tt := range tests {
+		output := interpret(t, tt.input)
+		if output != tt.expectedOutput {
+			t.Errorf("input %q: expected output %q, got %q", tt.input, tt.expectedOutput, output)
+		}
+	}
+}
+
+func TestInterpreter_BuiltinErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedPos     string
+	}{
+		{`purr len(1)`, "argument to len not supported, got INTEGER", "1:9"},
+		{`purr len("a", "b")`, "wrong number of arguments to len: want=1, got=2", "1:9"},
+		{`purr len()`, "wrong number of arguments to len: want=1, got=0", "1:9"},
+		{`purr type()`, "wrong number of arguments to type: want=1, got=0", "1:10"},
+		{`purr str(1, 2)`, "wrong number of arguments to str: want=1, got=2", "1:9"},
+		{`purr int("abc")`, "could not convert \"abc\" to INTEGER", "1:9"},
+		{`purr int("1.5")`, "could not convert \"1.5\" to INTEGER", "1:9"},
+		{`purr int([])`, "argument to int not supported, got ARRAY", "1:9"},
+		{`purr int(1e308 * 10)`, "could not convert Infinity to INTEGER", "1:9"},
+		{`purr float("abc")`, "could not convert \"abc\" to FLOAT", "1:11"},
+		{`purr float(true)`, "argument to float not supported, got BOOLEAN", "1:11"},
+		{`purr push(1, 2)`, "first argument to push must be ARRAY, got INTEGER", "1:10"},
+		{`purr push([])`, "wrong number of arguments to push: want=2, got=1", "1:10"},
+		{`purr keys([1])`, "argument to keys must be MAP, got ARRAY", "1:10"},
+		{`purr input(1, 2)`, "wrong number of arguments to input: want=0 or 1, got=2", "1:11"},
+		{`meow f() { claw len(5) } purr f()`, "argument to len not supported, got INTEGER", "1:20"},
+	}
+
+	for _, tt := range tests {
+		_, result := evaluate(t, tt.input)
+
+		err, ok := result.(*object.Error)
+		if !ok {
+			t.Errorf("input %q: expected *object.Error, got %T (%+v)", tt.input, result, result)
+			continue
+		}
+		if err.Message != tt.expectedMessage {
+			t.Errorf("input %q: expected message %q, got %q", tt.input, tt.expectedMessage, err.Message)
+		}
+		if err.Token.Pos.String() != tt.expectedPos {
+			t.Errorf("input %q: expected error at %s, got %s", tt.input, tt.expectedPos, err.Token.Pos)
+		}
+	}
+}
+
+func TestInterpreter_BuiltinInput(t *testing.T) {
+	input := `
+    lick name = input("Name? ")
+    lick age = int(input())
+    purr "{name} is {age}"
+    purr input()
+    purr input()`
+
+	program := parse(input)
+
+	forEachBackend(t, func(t *testing.T, b backend) {
+		var out bytes.Buffer
+		engine := b.new(&out, &out)
+		engine.SetInput(strings.NewReader("Mochi\r\n3\nlast line"))
+		result := engine.Interpret(program)
+
+		if isError(result) {
+			t.Fatalf("unexpected error %q", result.Inspect())
+		}
+
+		expectedOutput := "Name? Mochi is 3\nlast line\nnull\n"
+		if out.String() != expectedOutput {
+			t.Errorf("expected output %q, got %q", expectedOutput, out.String())
+		}
+	})
+}
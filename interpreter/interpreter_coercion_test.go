@@ -0,0 +1,181 @@
+package interpreter_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/AlyxPink/meowlang/object"
+)
+
+// coercionOperands holds a source expression for a value of each object type.
+var coercionOperands = map[object.ObjectType]string{
+	object.INTEGER_OBJ:  "7",
+	object.FLOAT_OBJ:    "1.5",
+	object.STRING_OBJ:   `"cat"`,
+	object.BOOLEAN_OBJ:  "true",
+	object.NULL_OBJ:     "nothing()",
+	object.FUNCTION_OBJ: "fn",
+	object.ARRAY_OBJ:    "[1]",
+	object.MAP_OBJ:      `{"a": 1}`,
+	object.BUILTIN_OBJ:  "len",
+}
+
+const coercionPrelude = `
+meow nothing() { claw }
+meow fn(x) { claw x }
+`
+
+func TestInterpreter_PlusCoercionMatrix(t *testing.T) {
+	tests := []struct {
+		left, right    object.ObjectType
+		expectedOutput string
+		expectedError  string
+	}{
+		{object.INTEGER_OBJ, object.INTEGER_OBJ, "14", ""},
+		{object.INTEGER_OBJ, object.FLOAT_OBJ, "8.5", ""},
+		{object.INTEGER_OBJ, object.STRING_OBJ, "7cat", ""},
+		{object.INTEGER_OBJ, object.BOOLEAN_OBJ, "", "type mismatch: INTEGER + BOOLEAN"},
+		{object.INTEGER_OBJ, object.NULL_OBJ, "", "type mismatch: INTEGER + NULL"},
+		{object.INTEGER_OBJ, object.FUNCTION_OBJ, "", "type mismatch: INTEGER + FUNCTION"},
+		{object.INTEGER_OBJ, object.ARRAY_OBJ, "", "type mismatch: INTEGER + ARRAY"},
+		{object.INTEGER_OBJ, object.MAP_OBJ, "", "type mismatch: INTEGER + MAP"},
+		{object.INTEGER_OBJ, object.BUILTIN_OBJ, "", "type mismatch: INTEGER + BUILTIN"},
+
+		{object.FLOAT_OBJ, object.INTEGER_OBJ, "8.5", ""},
+		{object.FLOAT_OBJ, object.FLOAT_OBJ, "3.0", ""},
+		{object.FLOAT_OBJ, object.STRING_OBJ, "1.5cat", ""},
+		{object.FLOAT_OBJ, object.BOOLEAN_OBJ, "", "type mismatch: FLOAT + BOOLEAN"},
+		{object.FLOAT_OBJ, object.NULL_OBJ, "", "type mismatch: FLOAT + NULL"},
+		{object.FLOAT_OBJ, object.FUNCTION_OBJ, "", "type mismatch: FLOAT + FUNCTION"},
+		{object.FLOAT_OBJ, object.ARRAY_OBJ, "", "type mismatch: FLOAT + ARRAY"},
+		{object.FLOAT_OBJ, object.MAP_OBJ, "", "type mismatch: FLOAT + MAP"},
+		{object.FLOAT_OBJ, object.BUILTIN_OBJ, "", "type mismatch: FLOAT + BUILTIN"},
+
+		{object.STRING_OBJ, object.FLOAT_OBJ, "cat1.5", ""},
+		{object.STRING_OBJ, object.INTEGER_OBJ, "cat7", ""},
+		{object.STRING_OBJ, object.STRING_OBJ, "catcat", ""},
+		{object.STRING_OBJ, object.BOOLEAN_OBJ, "cattrue", ""},
+		{object.STRING_OBJ, object.NULL_OBJ, "catnull", ""},
+		{object.STRING_OBJ, object.FUNCTION_OBJ, "catmeow(x) { ... }", ""},
+		{object.STRING_OBJ, object.ARRAY_OBJ, "cat[1]", ""},
+		{object.STRING_OBJ, object.MAP_OBJ, `cat{"a": 1}`, ""},
+		{object.STRING_OBJ, object.BUILTIN_OBJ, "catbuiltin len", ""},
+
+		{object.BOOLEAN_OBJ, object.FLOAT_OBJ, "", "type mismatch: BOOLEAN + FLOAT"},
+		{object.BOOLEAN_OBJ, object.INTEGER_OBJ, "", "type mismatch: BOOLEAN + INTEGER"},
+		{object.BOOLEAN_OBJ, object.STRING_OBJ, "truecat", ""},
+		{object.BOOLEAN_OBJ, object.BOOLEAN_OBJ, "", "unknown operator: BOOLEAN + BOOLEAN"},
+		{object.BOOLEAN_OBJ, object.NULL_OBJ, "", "type mismatch: BOOLEAN + NULL"},
+		{object.BOOLEAN_OBJ, object.FUNCTION_OBJ, "", "type mismatch: BOOLEAN + FUNCTION"},
+		{object.BOOLEAN_OBJ, object.ARRAY_OBJ, "", "type mismatch: BOOLEAN + ARRAY"},
+		{object.BOOLEAN_OBJ, object.MAP_OBJ, "", "type mismatch: BOOLEAN + MAP"},
+		{object.BOOLEAN_OBJ, object.BUILTIN_OBJ, "", "type mismatch: BOOLEAN + BUILTIN"},
+
+		{object.NULL_OBJ, object.FLOAT_OBJ, "", "type mismatch: NULL + FLOAT"},
+		{object.NULL_OBJ, object.INTEGER_OBJ, "", "type mismatch: NULL + INTEGER"},
+		{object.NULL_OBJ, object.STRING_OBJ, "nullcat", ""},
+		{object.NULL_OBJ, object.BOOLEAN_OBJ, "", "type mismatch: NULL + BOOLEAN"},
+		{object.NULL_OBJ, object.NULL_OBJ, "", "unknown operator: NULL + NULL"},
+		{object.NULL_OBJ, object.FUNCTION_OBJ, "", "type mismatch: NULL + FUNCTION"},
+		{object.NULL_OBJ, object.ARRAY_OBJ, "", "type mismatch: NULL + ARRAY"},
+		{object.NULL_OBJ, object.MAP_OBJ, "", "type mismatch: NULL + MAP"},
+		{object.NULL_OBJ, object.BUILTIN_OBJ, "", "type mismatch: NULL + BUILTIN"},
+
+		{object.FUNCTION_OBJ, object.FLOAT_OBJ, "", "type mismatch: FUNCTION + FLOAT"},
+		{object.FUNCTION_OBJ, object.INTEGER_OBJ, "", "type mismatch: FUNCTION + INTEGER"},
+		{object.FUNCTION_OBJ, object.STRING_OBJ, "meow(x) { ... }cat", ""},
+		{object.FUNCTION_OBJ, object.BOOLEAN_OBJ, "", "type mismatch: FUNCTION + BOOLEAN"},
+		{object.FUNCTION_OBJ, object.NULL_OBJ, "", "type mismatch: FUNCTION + NULL"},
+		{object.FUNCTION_OBJ, object.FUNCTION_OBJ, "", "unknown operator: FUNCTION + FUNCTION"},
+		{object.FUNCTION_OBJ, object.ARRAY_OBJ, "", "type mismatch: FUNCTION + ARRAY"},
+		{object.FUNCTION_OBJ, object.MAP_OBJ, "", "type mismatch: FUNCTION + MAP"},
+		{object.FUNCTION_OBJ, object.BUILTIN_OBJ, "", "type mismatch: FUNCTION + BUILTIN"},
+
+		{object.ARRAY_OBJ, object.INTEGER_OBJ, "", "type mismatch: ARRAY + INTEGER"},
+		{object.ARRAY_OBJ, object.FLOAT_OBJ, "", "type mismatch: ARRAY + FLOAT"},
+		{object.ARRAY_OBJ, object.STRING_OBJ, "[1]cat", ""},
+		{object.ARRAY_OBJ, object.BOOLEAN_OBJ, "", "type mismatch: ARRAY + BOOLEAN"},
+		{object.ARRAY_OBJ, object.NULL_OBJ, "", "type mismatch: ARRAY + NULL"},
+		{object.ARRAY_OBJ, object.FUNCTION_OBJ, "", "type mismatch: ARRAY + FUNCTION"},
+		{object.ARRAY_OBJ, object.ARRAY_OBJ, "", "unknown operator: ARRAY + ARRAY"},
+		{object.ARRAY_OBJ, object.MAP_OBJ, "", "type mismatch: ARRAY + MAP"},
+		{object.ARRAY_OBJ, object.BUILTIN_OBJ, "", "type mismatch: ARRAY + BUILTIN"},
+
+		{object.MAP_OBJ, object.INTEGER_OBJ, "", "type mismatch: MAP + INTEGER"},
+		{object.MAP_OBJ, object.FLOAT_OBJ, "", "type mismatch: MAP + FLOAT"},
+		{object.MAP_OBJ, object.STRING_OBJ, `{"a": 1}cat`, ""},
+		{object.MAP_OBJ, object.BOOLEAN_OBJ, "", "type mismatch: MAP + BOOLEAN"},
+		{object.MAP_OBJ, object.NULL_OBJ, "", "type mismatch: MAP + NULL"},
+		{object.MAP_OBJ, object.FUNCTION_OBJ, "", "type mismatch: MAP + FUNCTION"},
+		{object.MAP_OBJ, object.ARRAY_OBJ, "", "type mismatch: MAP + ARRAY"},
+		{object.MAP_OBJ, object.MAP_OBJ, "", "unknown operator: MAP + MAP"},
+		{object.MAP_OBJ, object.BUILTIN_OBJ, "", "type mismatch: MAP + BUILTIN"},
+
+		{object.BUILTIN_OBJ, object.INTEGER_OBJ, "", "type mismatch: BUILTIN + INTEGER"},
+		{object.BUILTIN_OBJ, object.FLOAT_OBJ, "", "type mismatch: BUILTIN + FLOAT"},
+		{object.BUILTIN_OBJ, object.STRING_OBJ, "builtin lencat", ""},
+		{object.BUILTIN_OBJ, object.BOOLEAN_OBJ, "", "type mismatch: BUILTIN + BOOLEAN"},
+		{object.BUILTIN_OBJ, object.NULL_OBJ, "", "type mismatch: BUILTIN + NULL"},
+		{object.BUILTIN_OBJ, object.FUNCTION_OBJ, "", "type mismatch: BUILTIN + FUNCTION"},
+		{object.BUILTIN_OBJ, object.ARRAY_OBJ, "", "type mismatch: BUILTIN + ARRAY"},
+		{object.BUILTIN_OBJ, object.MAP_OBJ, "", "type mismatch: BUILTIN + MAP"},
+		{object.BUILTIN_OBJ, object.BUILTIN_OBJ, "", "unknown operator: BUILTIN + BUILTIN"},
+	}
+
+	if len(tests) != len(coercionOperands)*len(coercionOperands) {
+		t.Fatalf("the matrix does not cover every pair of object types")
+	}
+
+	for _, tt := range tests {
+		input := fmt.Sprintf("%spurr %s + %s", coercionPrelude, coercionOperands[tt.left], coercionOperands[tt.right])
+		testCoercion(t, input, tt.expectedOutput, tt.expectedError)
+	}
+}
+
+func TestInterpreter_MismatchedTypesMatrix(t *testing.T) {
+	for left := range coercionOperands {
+		for right := range coercionOperands {
+			if left == right || isNumberType(left) && isNumberType(right) {
+				continue // integers and floats are combined by promotion
+			}
+
+			for _, operator := range []string{"-", "*", "/", "<", ">", "<=", ">="} {
+				input := fmt.Sprintf("%spurr %s %s %s", coercionPrelude, coercionOperands[left], operator, coercionOperands[right])
+				testCoercion(t, input, "", fmt.Sprintf("type mismatch: %s %s %s", left, operator, right))
+			}
+
+			// Values of different types are never equal
+			input := fmt.Sprintf("%spurr %s == %s", coercionPrelude, coercionOperands[left], coercionOperands[right])
+			testCoercion(t, input, "false", "")
+			input = fmt.Sprintf("%spurr %s != %s", coercionPrelude, coercionOperands[left], coercionOperands[right])
+			testCoercion(t, input, "true", "")
+		}
+	}
+}
+
+func isNumberType(t object.ObjectType) bool {
+	return t == object.INTEGER_OBJ || t == object.FLOAT_OBJ
+}
+
+func testCoercion(t *testing.T, input, expectedOutput, expectedError string) {
+	t.Helper()
+
+	output, result := evaluate(t, input)
+
+	if expectedError != "" {
+		err, ok := result.(*object.Error)
+		if !ok {
+			t.Errorf("input %q: expected error %q, got %T (%+v)", input, expectedError, result, result)
+		} else if err.Message != expectedError {
+			t.Errorf("input %q: expected error %q, got %q", input, expectedError, err.Message)
+		}
+		return
+	}
+
+	if isError(result) {
+		t.Errorf("input %q: unexpected error %q", input, result.Inspect())
+	}
+	if output != expectedOutput+"\n" {
+		t.Errorf("input %q: expected output %q, got %q", input, expectedOutput+"\n", output)
+	}
+}
@@ -0,0 +1,313 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlyxPink/meowlang/interpreter"
+	"github.com/AlyxPink/meowlang/lexer"
+	"github.com/AlyxPink/meowlang/object"
+	"github.com/AlyxPink/meowlang/parser"
+)
+
+// interpretWithLimits runs a program with limits on each backend and returns
+// its output and error, if any. It fails the test if the backends disagree.
+func interpretWithLimits(t *testing.T, ctx context.Context, input string, limits interpreter.Limits) (string, *object.Error) {
+	t.Helper()
+
+	l := lexer.NewLexer(input)
+	p := parser.NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+
+	var output string
+	var first object.Object
+	for index, b := range backends {
+		var out bytes.Buffer
+		engine := b.new(&out, &out)
+		engine.SetInput(strings.NewReader(""))
+		engine.SetLimits(limits)
+		result := engine.InterpretContext(ctx, program)
+
+		if index == 0 {
+			output, first = out.String(), result
+			continue
+		}
+		if out.String() != output || describe(result) != describe(first) {
+			t.Errorf("%q: the %s prints %q and returns %s, the %s prints %q and returns %s", input,
+				b.name, out.String(), describe(result), backends[0].name, output, describe(first))
+		}
+	}
+
+	err, _ := first.(*object.Error)
+	return output, err
+}
+
+func TestInterpreter_Limits(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		limits         interpreter.Limits
+		expectedOutput string
+		expectedLimit  interpreter.Limit
+		expectedError  string
+	}{
+		{
+			"endless loop",
+			"lick n = 0\nscratch (true) {\n  n = n + 1\n}",
+			interpreter.Limits{MaxSteps: 100},
+			"", interpreter.LimitSteps,
+			"3:3: step limit of 100 exceeded",
+		},
+		{
+			"unbounded recursion",
+			"meow f(n) {\n  claw f(n + 1)\n}\nf(0)",
+			interpreter.Limits{MaxCallDepth: 50},
+			"", interpreter.LimitCallDepth,
+			"2:9: call depth limit of 50 exceeded",
+		},
+		{
+			"too much output",
+			"scratch (true) {\n  purr \"meow\"\n}",
+			interpreter.Limits{MaxOutputBytes: 12},
+			"meow\nmeow\n", interpreter.LimitOutput,
+			"2:3: output limit of 12 bytes exceeded",
+		},
+		{
+			"too much output from a built-in function",
+			`purr "ok" input("What is your name? ")`,
+			interpreter.Limits{MaxOutputBytes: 12},
+			"ok\n", interpreter.LimitOutput,
+			"1:16: output limit of 12 bytes exceeded",
+		},
+		{
+			"too long",
+			"scratch (true) {\n  nap(1)\n}",
+			interpreter.Limits{MaxDuration: 20 * time.Millisecond},
+			"", interpreter.LimitDuration,
+			"2:3: wall time limit of 20ms exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		output, err := interpretWithLimits(t, context.Background(), tt.input, tt.limits)
+
+		if err == nil {
+			t.Errorf("%s: expected an error, got none", tt.name)
+			continue
+		}
+		if err.Error() != tt.expectedError {
+			t.Errorf("%s: expected error %q, got %q", tt.name, tt.expectedError, err.Error())
+		}
+		var limitErr *interpreter.LimitError
+		if !errors.As(err, &limitErr) || limitErr.Limit != tt.expectedLimit {
+			t.Errorf("%s: expected a %s limit error, got %#v", tt.name, tt.expectedLimit, err.Err)
+		}
+		if output != tt.expectedOutput {
+			t.Errorf("%s: expected output %q, got %q", tt.name, tt.expectedOutput, output)
+		}
+	}
+}
+
+func TestInterpreter_DefaultCallDepth(t *testing.T) {
+	_, err := interpretWithLimits(t, context.Background(), `meow f(n) { claw f(n + 1) } f(0)`, interpreter.Limits{})
+
+	var limitErr *interpreter.LimitError
+	if err == nil || !errors.As(err, &limitErr) || limitErr.Limit != interpreter.LimitCallDepth {
+		t.Fatalf("expected a call depth limit error, got %v", err)
+	}
+	if limitErr.Max != interpreter.DefaultMaxCallDepth {
+		t.Errorf("expected the default limit of %d, got %d", interpreter.DefaultMaxCallDepth, limitErr.Max)
+	}
+	if err.Error() != "1:19: call depth limit of 10000 exceeded" {
+		t.Errorf("expected error %q, got %q", "1:19: call depth limit of 10000 exceeded", err.Error())
+	}
+}
+
+func TestInterpreter_WithinLimits(t *testing.T) {
+	input := `
+    meow fact(n) {
+        hiss (n < 2) { claw 1 }
+        claw n * fact(n - 1)
+    }
+    purr fact(10)`
+	limits := interpreter.Limits{MaxSteps: 1000, MaxCallDepth: 10, MaxOutputBytes: 8, MaxDuration: time.Minute}
+
+	output, err := interpretWithLimits(t, context.Background(), input, limits)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if output != "3628800\n" {
+		t.Errorf("expected output %q, got %q", "3628800\n", output)
+	}
+}
+
+func TestInterpreter_StepLimitStopsAtTheSameNode(t *testing.T) {
+	input := `
+    lick a = [1, 2 + 3]
+    meow double(x) { claw x * 2 }
+    purr double(a[1]) + 1
+    hiss (a[0] < 2) { purr "small" } growl { purr "big" }
+    purr missing`
+
+	// The backends count steps differently, each limit stops them at the same node.
+	for limit := int64(1); limit <= 60; limit++ {
+		_, err := interpretWithLimits(t, context.Background(), input, interpreter.Limits{MaxSteps: limit})
+		if err == nil {
+			t.Fatalf("limit %d: expected an error, got none", limit)
+		}
+	}
+}
+
+func TestInterpreter_LimitsResetOnEachRun(t *testing.T) {
+	program := parse(`purr "meow"`)
+
+	forEachBackend(t, func(t *testing.T, b backend) {
+		var out bytes.Buffer
+		engine := b.new(&out, &out)
+		engine.SetLimits(interpreter.Limits{MaxSteps: 5, MaxOutputBytes: 5})
+
+		for run := 1; run <= 3; run++ {
+			if result := engine.InterpretContext(context.Background(), program); isError(result) {
+				t.Fatalf("run %d: unexpected error %v", run, result)
+			}
+		}
+		if out.String() != "meow\nmeow\nmeow\n" {
+			t.Errorf("expected output %q, got %q", "meow\nmeow\nmeow\n", out.String())
+		}
+	})
+}
+
+func TestInterpreter_ContextCanceled(t *testing.T) {
+	program := parse("lick n = 0\nscratch (true) {\n  n = n + 1\n  nap(1)\n}")
+
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx, cancel := context.WithCancel(context.Background())
+		engine := b.new(io.Discard, io.Discard)
+		engine.SetSleeper(interpreter.SleeperFunc(func(d time.Duration) {
+			if n, _ := engine.Env().Get("n"); n.Inspect() == "3" {
+				cancel()
+			}
+		}))
+		result := engine.InterpretContext(ctx, program)
+
+		err, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error, got %T (%+v)", result, result)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err.Err)
+		}
+		if err.Error() != "4:3: context canceled" {
+			t.Errorf("expected error %q, got %q", "4:3: context canceled", err.Error())
+		}
+	})
+}
+
+func TestInterpreter_InputInterrupted(t *testing.T) {
+	program := parse("lick name = input()\npurr name")
+
+	forEachBackend(t, func(t *testing.T, b backend) {
+		// The input stays open, without ever sending a line.
+		in, w := io.Pipe()
+		defer w.Close()
+
+		engine := b.new(io.Discard, io.Discard)
+		engine.SetInput(in)
+		engine.SetLimits(interpreter.Limits{MaxDuration: 20 * time.Millisecond})
+
+		done := make(chan object.Object, 1)
+		go func() { done <- engine.InterpretContext(context.Background(), program) }()
+
+		select {
+		case result := <-done:
+			err, ok := result.(*object.Error)
+			if !ok {
+				t.Fatalf("expected an error, got %T (%+v)", result, result)
+			}
+			var limitErr *interpreter.LimitError
+			if !errors.As(err, &limitErr) || limitErr.Limit != interpreter.LimitDuration {
+				t.Errorf("expected a wall time limit error, got %v", err)
+			}
+			if err.Token.Pos.String() != "1:18" {
+				t.Errorf("expected the error at 1:18, got %s", err.Token.Pos)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected input to be interrupted by the wall time limit")
+		}
+	})
+}
+
+func TestInterpreter_NestedRunKeepsLimits(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		limits        interpreter.Limits
+		expectedLimit interpreter.Limit
+	}{
+		{
+			"steps are still counted",
+			`lick n = 0 scratch (n < 30) { n = n + 1 } cb() scratch (n < 60) { n = n + 1 }`,
+			interpreter.Limits{MaxSteps: 300}, interpreter.LimitSteps,
+		},
+		{
+			"wall time is still limited",
+			`cb() scratch (true) {}`,
+			interpreter.Limits{MaxDuration: 20 * time.Millisecond}, interpreter.LimitDuration,
+		},
+	}
+
+	for _, tt := range tests {
+		program := parse(`meow f() { claw 1 } ` + tt.input)
+
+		forEachBackend(t, func(t *testing.T, b backend) {
+			engine := b.new(io.Discard, io.Discard)
+			engine.SetLimits(tt.limits)
+			// cb runs a call of its own while the program runs.
+			engine.Env().Set("cb", &object.Builtin{Name: "cb", Fn: func(rt object.Runtime, args ...object.Object) object.Object {
+				f, _ := engine.Env().Get("f")
+				return engine.CallContext(context.Background(), f)
+			}})
+
+			done := make(chan object.Object, 1)
+			go func() { done <- engine.InterpretContext(context.Background(), program) }()
+
+			select {
+			case result := <-done:
+				var limitErr *interpreter.LimitError
+				err, _ := result.(*object.Error)
+				if err == nil || !errors.As(err, &limitErr) || limitErr.Limit != tt.expectedLimit {
+					t.Errorf("%s: expected a %s limit error, got %v", tt.name, tt.expectedLimit, result)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("%s: expected the program to be stopped", tt.name)
+			}
+		})
+	}
+}
+
+func TestInterpreter_ContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := interpretWithLimits(t, ctx, `nap(60)`, interpreter.Limits{MaxDuration: time.Minute})
+
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	var limitErr *interpreter.LimitError
+	if errors.As(err, &limitErr) {
+		t.Errorf("expected the deadline of the context, not a limit error, got %v", limitErr)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected nap to be interrupted, it took %s", elapsed)
+	}
+}
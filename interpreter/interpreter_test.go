@@ -1,39 +1,271 @@
-package interpreter
+package interpreter_test
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/AlyxPink/meowlang/lexer"
-	"github.com/AlyxPink/meowlang/parser"
+	"github.com/AlyxPink/meowlang/interpreter"
+	"github.com/AlyxPink/meowlang/object"
 )
 
-func interpret(input string) string {
-	l := lexer.NewLexer(input)
-	p := parser.NewParser(l.Tokenize())
-	program := p.ParseProgram()
-
-	var out bytes.Buffer
-	interpreter := NewInterpreterWithOutput(&out)
-	interpreter.Interpret(program)
-
-	return out.String()
-}
-
 func TestInterpreter_IntegerArithmetic(t *testing.T) {
 	input := `purr 1 + 2`
 	expectedOutput := "3\n"
-	output := interpret(input)
+	output := interpret(t, input)
 
 	if output != expectedOutput {
 		t.Errorf("expected output %q, got %q", expectedOutput, output)
 	}
 }
 
+func TestInterpreter_FloatArithmetic(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{`purr 1.5`, "1.5\n"},
+		{`purr 1.5 + 1.5`, "3.0\n"},
+		{`purr 7 / 2`, "3\n"},
+		{`purr 7 / 2.0`, "3.5\n"},
+		{`purr 7.0 / 2`, "3.5\n"},
+		{`purr 0.1 + 0.2`, "0.30000000000000004\n"},
+		{`purr 2 * 0.25`, "0.5\n"},
+		{`purr 1 - 1.5`, "-0.5\n"},
+		{`purr -2.5`, "-2.5\n"},
+		{`purr 1e3`, "1000.0\n"},
+		{`purr 2.5e-3`, "0.0025\n"},
+		{`purr 1e21`, "1e+21\n"},
+		{`purr 1e-5`, "1e-05\n"},
+		{`purr 1e308 * 10`, "Infinity\n"},
+		{`purr 1 < 1.5`, "true\n"},
+		{`purr 2.0 >= 2`, "true\n"},
+		{`purr 1 == 1.0`, "true\n"},
+		{`purr 1.5 != 1.5`, "false\n"},
+		{`purr "avg: " + (3 + 4) / 2.0`, "avg: 3.5\n"},
+		{`hiss (0.0) { purr "yes" } growl { purr "no" }`, "no\n"},
+	}
+
+	for _, tt := range tests {
+		output := interpret(t, tt.input)
+		if output != tt.expectedOutput {
+			t.Errorf("input %q: expected output %q, got %q", tt.input, tt.expectedOutput, output)
+		}
+	}
+}
+
+func TestInterpreter_BigIntegers(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{`purr 9223372036854775807 + 1`, "9223372036854775808\n"},
+		{`purr -9223372036854775808 - 1`, "-9223372036854775809\n"},
+		{`purr 4611686018427387904 * 2`, "9223372036854775808\n"},
+		{`purr -9223372036854775808 / -1`, "9223372036854775808\n"},
+		{`purr -(-9223372036854775808)`, "9223372036854775808\n"},
+		{`purr 99999999999999999999`, "99999999999999999999\n"},
+		{`purr 99999999999999999999 / 10`, "9999999999999999999\n"},
+		{`purr -99999999999999999999 / 7`, "-14285714285714285714\n"},
+		{`purr 99999999999999999999 - 99999999999999999998`, "1\n"},
+		{`purr 99999999999999999999 > 9223372036854775807`, "true\n"},
+		{`purr 99999999999999999999 == 99999999999999999999`, "true\n"},
+		{`purr 99999999999999999999 != 1`, "true\n"},
+		{`purr 99999999999999999999 + 0.5`, "100000000000000000000.0\n"},
+		{`purr "big: " + 99999999999999999999`, "big: 99999999999999999999\n"},
+		{`purr 9223372036854775807 * 9223372036854775807`, "85070591730234615847396907784232501249\n"},
+		{`
+		meow factorial(n) {
+			hiss (n <= 1) { claw 1 }
+			claw n * factorial(n - 1)
+		}
+		purr factorial(30)`, "265252859812191058636308480000000\n"},
+	}
+
+	for _, tt := range tests {
+		output := interpret(t, tt.input)
+		if output != tt.expectedOutput {
+			t.Errorf("input %q: expected output %q, got %q", tt.input, tt.expectedOutput, output)
+		}
+	}
+}
+
+func TestInterpreter_BigIntegersShrinkBack(t *testing.T) {
+	_, result := evaluate(t, `lick x = (9223372036854775807 + 1) - 1 x`)
+
+	integer, ok := result.(*object.Integer)
+	if !ok {
+		t.Fatalf("result not *object.Integer. got=%T (%+v)", result, result)
+	}
+	if integer.IsBig() || integer.Value != 9223372036854775807 {
+		t.Errorf("expected small integer 9223372036854775807, got %+v", integer)
+	}
+}
+
+func TestInterpreter_Arrays(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{`purr [1, 2 * 2, 3 + 3]`, "[1, 4, 6]\n"},
+		{`purr []`, "[]\n"},
+		{`purr [1, "two", 3.0, true, [4]]`, "[1, \"two\", 3.0, true, [4]]\n"},
+		{`purr [1, 2, 3][0]`, "1\n"},
+		{`lick xs = [1, 2, 3] purr xs[1 + 1]`, "3\n"},
+		{`lick xs = [1, 2, 3] purr xs[-1]`, "3\n"},
+		{`lick xs = [1, 2, 3] purr xs[-3]`, "1\n"},
+		{`lick grid = [[1, 2], [3, 4]] purr grid[1][0]`, "3\n"},
+		{`meow first(xs) { claw xs[0] } purr first([7, 8])`, "7\n"},
+		{`meow pair() { claw [1, 2] } purr pair()[1]`, "2\n"},
+		{`lick xs = [1, 2, 3] xs[0] = 10 xs[-1] = "last" purr xs`, "[10, 2, \"last\"]\n"},
+		{`lick grid = [[1, 2], [3, 4]] grid[1][0] = 9 purr grid`, "[[1, 2], [9, 4]]\n"},
+		{`lick xs = [1] lick ys = xs ys[0] = 2 purr xs`, "[2]\n"},
+		{`meow clear(xs) { xs[0] = 0 } lick xs = [5] clear(xs) purr xs`, "[0]\n"},
+		{`
+		lick xs = [0, 0, 0]
+		lick i = 0
+		scratch (i < 3) {
+			xs[i] = i * i
+			i = i + 1
+		}
+		purr xs`, "[0, 1, 4]\n"},
+		{`purr "xs: " + [1, 2]`, "xs: [1, 2]\n"},
+		{`lick xs = [1] purr xs == xs`, "true\n"},
+		{`purr [1] == [1]`, "false\n"},
+	}
+
+	for _, tt := range tests {
+		output := interpret(t, tt.input)
+		if output != tt.expectedOutput {
+			t.Errorf("input %q: expected output %q, got %q", tt.input, tt.expectedOutput, output)
+		}
+	}
+}
+
+func TestInterpreter_Maps(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{`purr {"name": "Mochi", "age": 3}`, "{\"name\": \"Mochi\", \"age\": 3}\n"},
+		{`purr {}`, "{}\n"},
+		{`purr {3: "c", 1: "a", 2: "b"}`, "{3: \"c\", 1: \"a\", 2: \"b\"}\n"},
+		{`purr {true: "yes", false: "no"}[1 < 2]`, "yes\n"},
+		{`purr {"a": 1, "a": 2}`, "{\"a\": 2}\n"},
+		{`lick m = {"name": "Mochi"} purr m["name"]`, "Mochi\n"},
+		{`lick m = {"na" + "me": "Mochi"} purr m["name"]`, "Mochi\n"},
+		{`lick m = {1: "one"} purr m[2 - 1]`, "one\n"},
+		{`lick m = {99999999999999999999: "big"} purr m[99999999999999999998 + 1]`, "big\n"},
+		{`lick m = {"1": "string", 1: "integer"} purr m[1] + " " + m["1"]`, "integer string\n"},
+		{`lick m = {} purr m["missing"]`, "null\n"},
+		{`lick m = {"a": 1} m["b"] = 2 m["a"] = 3 purr m`, "{\"a\": 3, \"b\": 2}\n"},
+		{`lick m = {"a": 1, "b": 2, "c": 3} shoo m["b"] purr m`, "{\"a\": 1, \"c\": 3}\n"},
+		{`lick m = {"a": 1, "b": 2} shoo m["a"] m["a"] = 3 purr m`, "{\"b\": 2, \"a\": 3}\n"},
+		{`lick m = {"a": 1} shoo m["missing"] purr m`, "{\"a\": 1}\n"},
+		{`lick m = {"cats": ["Mochi"]} m["cats"][0] = "Tofu" purr m`, "{\"cats\": [\"Tofu\"]}\n"},
+		{`lick m = {} lick alias = m alias["k"] = "v" purr m`, "{\"k\": \"v\"}\n"},
+		{`lick xs = [1, 2, 3] shoo xs[0] shoo xs[-1] purr xs`, "[2]\n"},
+		{`purr "cat: " + {"name": "Mochi"}`, "cat: {\"name\": \"Mochi\"}\n"},
+		{`lick m = {"name": "Mochi"} purr "Hello, {m["name"]}!"`, "Hello, Mochi!\n"},
+	}
+
+	for _, tt := range tests {
+		output := interpret(t, tt.input)
+		if output != tt.expectedOutput {
+			t.Errorf("input %q: expected output %q, got %q", tt.input, tt.expectedOutput, output)
+		}
+	}
+}
+
+func TestInterpreter_CyclicCollections(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{`lick xs = [1, 2] xs[0] = xs purr xs`, "[[...], 2]\n"},
+		{`lick m = {"a": 1} m["b"] = m purr m`, "{\"a\": 1, \"b\": {...}}\n"},
+		{`lick xs = [] lick m = {"xs": xs} push(xs, m) purr m purr xs`, "{\"xs\": [{...}]}\n[{\"xs\": [...]}]\n"},
+		{`lick ys = [1] lick xs = [ys, ys] purr xs`, "[[1], [1]]\n"},
+		{`lick xs = [1] xs[0] = xs purr "{xs}"`, "[[...]]\n"},
+	}
+
+	for _, tt := range tests {
+		output := interpret(t, tt.input)
+		if output != tt.expectedOutput {
+			t.Errorf("input %q: expected output %q, got %q", tt.input, tt.expectedOutput, output)
+		}
+	}
+}
+
+func TestInterpreter_FunctionLiterals(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{`lick double = meow(x) { claw x * 2 } purr double(21)`, "42\n"},
+		{`purr meow(x) { claw x * 2 }`, "meow(x) { ... }\n"},
+		{`purr meow(a, b) { claw a + b }(1, 2)`, "3\n"},
+		{`lick result = meow() { claw "now" }() purr result`, "now\n"},
+		{`meow apply(f, x) { claw f(x) } purr apply(meow(x) { claw x + 1 }, 41)`, "42\n"},
+		{`meow twice(f) { claw meow(x) { claw f(f(x)) } } purr twice(meow(x) { claw x * 3 })(2)`, "18\n"},
+		{`lick fs = [meow(x) { claw x + 1 }, meow(x) { claw x - 1 }] purr fs[1](10)`, "9\n"},
+		{`lick ops = {"add": meow(a, b) { claw a + b }} purr ops["add"](2, 3)`, "5\n"},
+		{`
+		meow makeAdder(n) {
+			claw meow(x) { claw x + n }
+		}
+		lick addTwo = makeAdder(2)
+		lick addTen = makeAdder(10)
+		purr addTwo(1)
+		purr addTen(1)`, "3\n11\n"},
+		{`
+		meow makeCounter() {
+			lick count = 0
+			claw meow() {
+				count = count + 1
+				claw count
+			}
+		}
+		lick counter = makeCounter()
+		counter()
+		counter()
+		purr counter()
+		purr makeCounter()()`, "3\n1\n"},
+		{`
+		lick factorial = meow(n) {
+			hiss (n <= 1) { claw 1 }
+			claw n * factorial(n - 1)
+		}
+		purr factorial(5)`, "120\n"},
+		{`
+		meow outer() {
+			lick fib = meow(n) {
+				hiss (n < 2) { claw n }
+				claw fib(n - 1) + fib(n - 2)
+			}
+			claw fib(10)
+		}
+		purr outer()`, "55\n"},
+		{`lick x = 1 lick f = meow() { claw x } x = 2 purr f()`, "2\n"},
+	}
+
+	for _, tt := range tests {
+		output := interpret(t, tt.input)
+		if output != tt.expectedOutput {
+			t.Errorf("input %q: expected output %q, got %q", tt.input, tt.expectedOutput, output)
+		}
+	}
+}
+
 func TestInterpreter_StringConcatenation(t *testing.T) {
 	input := `purr "Hello" + " world"`
 	expectedOutput := "Hello world\n"
-	output := interpret(input)
+	output := interpret(t, input)
 
 	if output != expectedOutput {
 		t.Errorf("expected output %q, got %q", expectedOutput, output)
@@ -43,7 +275,7 @@ func TestInterpreter_StringConcatenation(t *testing.T) {
 func TestInterpreter_VariableAssignment(t *testing.T) {
 	input := `lick x = 42; purr x;`
 	expectedOutput := "42\n"
-	output := interpret(input)
+	output := interpret(t, input)
 
 	if output != expectedOutput {
 		t.Errorf("expected output %q, got %q", expectedOutput, output)
@@ -53,7 +285,7 @@ func TestInterpreter_VariableAssignment(t *testing.T) {
 func TestInterpreter_PrintStatement(t *testing.T) {
 	input := `purr 123`
 	expectedOutput := "123\n"
-	output := interpret(input)
+	output := interpret(t, input)
 
 	if output != expectedOutput {
 		t.Errorf("expected output %q, got %q", expectedOutput, output)
@@ -67,7 +299,7 @@ func TestInterpreter_FunctionDefinition(t *testing.T) {
     }
     purr double(5);`
 	expectedOutput := "10\n"
-	output := interpret(input)
+	output := interpret(t, input)
 
 	if output != expectedOutput {
 		t.Errorf("expected output %q, got %q", expectedOutput, output)
@@ -81,9 +313,665 @@ func TestInterpreter_FunctionCall(t *testing.T) {
     }
     purr addTen(10);`
 	expectedOutput := "20\n"
-	output := interpret(input)
+	output := interpret(t, input)
+
+	if output != expectedOutput {
+		t.Errorf("expected output %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestInterpreter_PrintInFunctions(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{`meow greet(name) { purr "Hello, " + name } greet("Mochi")`, "Hello, Mochi\n"},
+		{`
+		meow inner(x) { purr "inner " + x claw x * 2 }
+		meow outer(x) { purr "outer " + x purr inner(x + 1) }
+		outer(1)
+		purr "done"`, "outer 1\ninner 2\n4\ndone\n"},
+		{`
+		meow countdown(n) {
+			purr n
+			hiss (n > 0) { countdown(n - 1) }
+		}
+		countdown(3)`, "3\n2\n1\n0\n"},
+		{`
+		meow fib(n) {
+			hiss (n < 2) { claw n }
+			lick result = fib(n - 1) + fib(n - 2)
+			purr "fib(" + n + ") = " + result
+			claw result
+		}
+		fib(4)`, "fib(2) = 1\nfib(3) = 2\nfib(2) = 1\nfib(4) = 3\n"},
+		{`lick shout = meow(s) { purr s + "!" } shout("meow") purr len("x")`, "meow!\n1\n"},
+	}
+
+	for _, tt := range tests {
+		output := interpret(t, tt.input)
+		if output != tt.expectedOutput {
+			t.Errorf("%q: expected output %q, got %q", tt.input, tt.expectedOutput, output)
+		}
+	}
+}
+
+func TestInterpreter_FunctionsRestoreScope(t *testing.T) {
+	input := `
+    lick x = "global"
+    meow f(x) {
+        hiss (x > 0) { f(x - 1) }
+        purr x
+    }
+    f(2)
+    purr x`
+
+	output := interpret(t, input)
+
+	if output != "0\n1\n2\nglobal\n" {
+		t.Errorf("expected output %q, got %q", "0\n1\n2\nglobal\n", output)
+	}
+}
+
+func TestInterpreter_FunctionsShareSettings(t *testing.T) {
+	program := parse(`meow wait(n) { nap(n) claw input() } purr wait(2)`)
+
+	forEachBackend(t, func(t *testing.T, b backend) {
+		var out bytes.Buffer
+		var sleeps []time.Duration
+		engine := b.new(&out, &out)
+		engine.SetInput(strings.NewReader("awake\n"))
+		engine.SetNapUnit(time.Millisecond)
+		engine.SetSleeper(interpreter.SleeperFunc(func(d time.Duration) {
+			sleeps = append(sleeps, d)
+		}))
+		engine.Interpret(program)
+
+		if !reflect.DeepEqual(sleeps, []time.Duration{2 * time.Millisecond}) {
+			t.Errorf("expected sleeps %v, got %v", []time.Duration{2 * time.Millisecond}, sleeps)
+		}
+		if out.String() != "awake\n" {
+			t.Errorf("expected output %q, got %q", "awake\n", out.String())
+		}
+	})
+}
+
+func TestInterpreter_IfStatement(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{`hiss (1 < 2) { purr "yes" }`, "yes\n"},
+		{`hiss (1 > 2) { purr "yes" }`, ""},
+		{`hiss (1 > 2) { purr "yes" } growl { purr "no" }`, "no\n"},
+		{`hiss (0) { purr "yes" } growl { purr "no" }`, "no\n"},
+		{`hiss ("") { purr "yes" } growl { purr "no" }`, "no\n"},
+		{`hiss ("meow") { purr "yes" } growl { purr "no" }`, "yes\n"},
+	}
+
+	for _, tt := range tests {
+		output := interpret(t, tt.input)
+		if output != tt.expectedOutput {
+			t.Errorf("input %q: expected output %q, got %q", tt.input, tt.expectedOutput, output)
+		}
+	}
+}
+
+func TestInterpreter_IfStatementChains(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{`lick a = 1 hiss (a < 5) { purr "less" } growl hiss (a > 5) { purr "greater" } growl { purr "equal" }`, "less\n"},
+		{`lick a = 9 hiss (a < 5) { purr "less" } growl hiss (a > 5) { purr "greater" } growl { purr "equal" }`, "greater\n"},
+		{`lick a = 5 hiss (a < 5) { purr "less" } growl hiss (a > 5) { purr "greater" } growl { purr "equal" }`, "equal\n"},
+	}
+
+	for _, tt := range tests {
+		output := interpret(t, tt.input)
+		if output != tt.expectedOutput {
+			t.Errorf("input %q: expected output %q, got %q", tt.input, tt.expectedOutput, output)
+		}
+	}
+}
+
+func TestInterpreter_WhileStatement(t *testing.T) {
+	input := `
+    lick a = 5
+    lick b = 8
+    scratch (a < b) {
+        purr a
+        a = a + 1
+    }
+    purr a`
+	expectedOutput := "5\n6\n7\n8\n"
+	output := interpret(t, input)
+
+	if output != expectedOutput {
+		t.Errorf("expected output %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestInterpreter_ReassignUpdatesOuterBinding(t *testing.T) {
+	input := `
+    lick count = 0
+    meow bump(n) {
+        count = count + n
+        claw count
+    }
+    lick first = bump(2)
+    lick second = bump(3)
+    purr count`
+	expectedOutput := "5\n"
+	output := interpret(t, input)
+
+	if output != expectedOutput {
+		t.Errorf("expected output %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestInterpreter_NapStatement(t *testing.T) {
+	tests := []struct {
+		unit           time.Duration
+		expectedSleeps []time.Duration
+		expectedOutput string
+	}{
+		{interpreter.DefaultNapUnit, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}, "1\n2\n3\n"},
+		{time.Millisecond, []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}, "1\n2\n3\n"},
+	}
+
+	input := `
+    lick a = 1
+    scratch (a < 4) {
+        purr a
+        nap(a)
+        a = a + 1
+    }`
+
+	program := parse(input)
+
+	forEachBackend(t, func(t *testing.T, b backend) {
+		for _, tt := range tests {
+			var out bytes.Buffer
+			var sleeps []time.Duration
+			engine := b.new(&out, &out)
+			engine.SetSleeper(interpreter.SleeperFunc(func(d time.Duration) {
+				sleeps = append(sleeps, d)
+			}))
+			engine.SetNapUnit(tt.unit)
+			engine.Interpret(program)
+
+			if out.String() != tt.expectedOutput {
+				t.Errorf("expected output %q, got %q", tt.expectedOutput, out.String())
+			}
+			if !reflect.DeepEqual(sleeps, tt.expectedSleeps) {
+				t.Errorf("expected sleeps %v, got %v", tt.expectedSleeps, sleeps)
+			}
+		}
+	})
+}
+
+func TestInterpreter_NapFraction(t *testing.T) {
+	program := parse(`nap(0.5) nap(1.25)`)
+
+	forEachBackend(t, func(t *testing.T, b backend) {
+		var sleeps []time.Duration
+		engine := b.new(io.Discard, io.Discard)
+		engine.SetSleeper(interpreter.SleeperFunc(func(d time.Duration) {
+			sleeps = append(sleeps, d)
+		}))
+		engine.Interpret(program)
+
+		expectedSleeps := []time.Duration{500 * time.Millisecond, 1250 * time.Millisecond}
+		if !reflect.DeepEqual(sleeps, expectedSleeps) {
+			t.Errorf("expected sleeps %v, got %v", expectedSleeps, sleeps)
+		}
+	})
+}
+
+func TestInterpreter_StreamsOutput(t *testing.T) {
+	program := parse(`purr "a" nap(1) purr "b" nap(1) purr "c"`)
+
+	forEachBackend(t, func(t *testing.T, b backend) {
+		// What was printed when each nap starts.
+		var out, stderr bytes.Buffer
+		var printed []string
+		engine := b.new(&out, &stderr)
+		engine.SetSleeper(interpreter.SleeperFunc(func(d time.Duration) {
+			printed = append(printed, out.String())
+		}))
+		engine.Interpret(program)
+
+		expected := []string{"a\n", "a\nb\n"}
+		if !reflect.DeepEqual(printed, expected) {
+			t.Errorf("expected output %q while napping, got %q", expected, printed)
+		}
+		if out.String() != "a\nb\nc\n" {
+			t.Errorf("expected output %q, got %q", "a\nb\nc\n", out.String())
+		}
+		if stderr.Len() != 0 {
+			t.Errorf("expected nothing on stderr, got %q", stderr.String())
+		}
+	})
+}
+
+func TestInterpreter_LargeProgram(t *testing.T) {
+	// A loop too long to jump over with 16 bits, and more distinct literals
+	// than 16 bits can index, which are all fine for the interpreter.
+	var src strings.Builder
+	src.WriteString("lick n = 0 lick i = 0 scratch (i < 2) {\n")
+	for range 20000 {
+		src.WriteString("  n = n + 1\n")
+	}
+	src.WriteString("  i = i + 1\n}\npurr n\nlick xs = [")
+	for i := range 70000 {
+		fmt.Fprintf(&src, "%d, ", i)
+	}
+	src.WriteString("0]\npurr len(xs)\n")
+
+	output, result := evaluate(t, src.String())
+
+	if isError(result) {
+		t.Fatalf("unexpected error %s", result.Inspect())
+	}
+	if output != "40000\n70001\n" {
+		t.Errorf("expected output %q, got %q", "40000\n70001\n", output)
+	}
+}
+
+func TestInterpreter_ReentrantCall(t *testing.T) {
+	program := parse(`
+    meow g(n) { hiss (n > 0) { claw g(n - 1) } claw 0 }
+    meow k() { claw 0 }
+    meow f() {
+        lick a = cb()
+        purr "between"
+        lick b = k()
+        claw a + b
+    }
+    purr f()`)
+
+	forEachBackend(t, func(t *testing.T, b backend) {
+		var out bytes.Buffer
+		engine := b.new(&out, &out)
+		// cb calls back into the program while it runs, deep enough for the
+		// frames of the calls in progress to move.
+		engine.Env().Set("cb", &object.Builtin{Name: "cb", Fn: func(rt object.Runtime, args ...object.Object) object.Object {
+			g, _ := engine.Env().Get("g")
+			if result := engine.Call(g, object.NewInteger(500)); isError(result) {
+				return result
+			}
+			return object.NewInteger(1)
+		}})
+
+		if result := engine.Interpret(program); isError(result) {
+			t.Fatalf("unexpected error %v", result)
+		}
+		if out.String() != "between\n1\n" {
+			t.Errorf("expected output %q, got %q", "between\n1\n", out.String())
+		}
+	})
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestInterpreter_PrintErrors(t *testing.T) {
+	program := parse(`lick a = 1 purr a a = 2`)
+
+	forEachBackend(t, func(t *testing.T, b backend) {
+		engine := b.new(failingWriter{}, failingWriter{})
+		result := engine.Interpret(program)
+
+		err, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error, got %T (%+v)", result, result)
+		}
+		if err.Error() != "1:12: cannot print: disk full" {
+			t.Errorf("expected error %q, got %q", "1:12: cannot print: disk full", err.Error())
+		}
+		if a, _ := engine.Env().Get("a"); a.Inspect() != "1" {
+			t.Errorf("expected the program to stop at the error, got a = %s", a.Inspect())
+		}
+	})
+}
+
+func TestInterpreter_BooleanExpressions(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{`purr true`, "true\n"},
+		{`purr false`, "false\n"},
+		{`purr 1 < 2`, "true\n"},
+		{`purr 1 > 2`, "false\n"},
+		{`purr 2 <= 2`, "true\n"},
+		{`purr 3 >= 4`, "false\n"},
+		{`purr 1 == 1`, "true\n"},
+		{`purr 1 != 1`, "false\n"},
+		{`purr "a" < "b"`, "true\n"},
+		{`purr "cat" == "cat"`, "true\n"},
+		{`purr "cat" != "dog"`, "true\n"},
+		{`purr "b" >= "a"`, "true\n"},
+		{`purr true == true`, "true\n"},
+		{`purr true != false`, "true\n"},
+		{`purr (1 < 2) == true`, "true\n"},
+		{`purr 1 == "1"`, "false\n"},
+		{`purr 1 != "1"`, "true\n"},
+		{`lick t = true hiss (t) { purr "yes" }`, "yes\n"},
+		{`hiss (false) { purr "yes" } growl { purr "no" }`, "no\n"},
+	}
+
+	for _, tt := range tests {
+		output := interpret(t, tt.input)
+		if output != tt.expectedOutput {
+			t.Errorf("input %q: expected output %q, got %q", tt.input, tt.expectedOutput, output)
+		}
+	}
+}
+
+func TestInterpreter_PrefixExpressions(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{`purr -5`, "-5\n"},
+		{`purr --5`, "5\n"},
+		{`lick x = 7 purr -x`, "-7\n"},
+		{`purr 10 - -3`, "13\n"},
+		{`purr -2 * 3`, "-6\n"},
+		{`purr !true`, "false\n"},
+		{`purr !false`, "true\n"},
+		{`purr !!true`, "true\n"},
+		{`purr !0`, "true\n"},
+		{`purr !5`, "false\n"},
+		{`purr !""`, "true\n"},
+		{`purr !(1 < 2)`, "false\n"},
+		{`hiss (!false) { purr "yes" }`, "yes\n"},
+	}
+
+	for _, tt := range tests {
+		output := interpret(t, tt.input)
+		if output != tt.expectedOutput {
+			t.Errorf("input %q: expected output %q, got %q", tt.input, tt.expectedOutput, output)
+		}
+	}
+}
+
+func TestInterpreter_PrefixErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`purr -"meow"`, "invalid operand for -: STRING"},
+		{`purr -true`, "invalid operand for -: BOOLEAN"},
+		{`lick x = -"meow" purr "unreachable"`, "invalid operand for -: STRING"},
+	}
+
+	for _, tt := range tests {
+		output, result := evaluate(t, tt.input)
+
+		err, ok := result.(*object.Error)
+		if !ok {
+			t.Errorf("input %q: expected *object.Error, got %T (%+v)", tt.input, result, result)
+			continue
+		}
+		if err.Message != tt.expectedMessage {
+			t.Errorf("input %q: expected message %q, got %q", tt.input, tt.expectedMessage, err.Message)
+		}
+		if output != "" {
+			t.Errorf("input %q: expected no output, got %q", tt.input, output)
+		}
+	}
+}
+
+func TestInterpreter_ReturnStopsFunction(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{`
+    meow early(a) {
+        claw a
+        claw a * 100
+    }
+    purr early(3)`, "3\n"},
+		{`
+    meow sign(a) {
+        hiss (a < 0) {
+            claw "negative"
+        }
+        claw "positive"
+    }
+    purr sign(-4)
+    purr sign(4)`, "negative\npositive\n"},
+		{`
+    meow firstAbove(limit) {
+        lick n = 0
+        scratch (true) {
+            hiss (n > limit) {
+                claw n
+            }
+            n = n + 1
+        }
+    }
+    purr firstAbove(5)`, "6\n"},
+		{`
+    meow nothing(a) {
+        claw
+    }
+    purr nothing(1)`, "null\n"},
+	}
+
+	for _, tt := range tests {
+		output := interpret(t, tt.input)
+		if output != tt.expectedOutput {
+			t.Errorf("input %q: expected output %q, got %q", tt.input, tt.expectedOutput, output)
+		}
+	}
+}
+
+func TestInterpreter_ReturnAtTopLevel(t *testing.T) {
+	tests := []string{
+		`claw 5`,
+		`purr 1 claw 2 purr 3`,
+		`hiss (true) { claw 5 }`,
+	}
+
+	for _, input := range tests {
+		output, result := evaluate(t, input)
+
+		err, ok := result.(*object.Error)
+		if !ok {
+			t.Errorf("input %q: expected *object.Error, got %T (%+v)", input, result, result)
+			continue
+		}
+		if err.Message != "claw can only be used inside a function" {
+			t.Errorf("input %q: unexpected error message %q", input, err.Message)
+		}
+		if output == "1\n3\n" {
+			t.Errorf("input %q: statements after a top level claw were executed", input)
+		}
+	}
+}
+
+func TestInterpreter_RuntimeErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedToken   string
+	}{
+		{`purr foobar`, "identifier not found: foobar", "foobar"},
+		{`purr 5 + true`, "type mismatch: INTEGER + BOOLEAN", "+"},
+		{`purr "a" - "b"`, "unknown operator: STRING - STRING", "-"},
+		{`purr true + false`, "unknown operator: BOOLEAN + BOOLEAN", "+"},
+		{`purr 10 / 0`, "division by zero", "/"},
+		{`lick x = 5 purr x(1)`, "not a function: INTEGER", "("},
+		{`meow add(a, b) { claw a + b } purr add(1)`, "wrong number of arguments: want=2, got=1", "("},
+		{`meow boom() { claw missing } purr boom()`, "identifier not found: missing", "missing"},
+		{`x = 5`, "cannot assign to undeclared variable x, declare it with lick first", "x"},
+		{`nap("long")`, "nap duration must be a number, got STRING", "nap"},
+		{`nap(-1)`, "nap duration must not be negative, got -1", "nap"},
+		{`nap(-0.5)`, "nap duration must not be negative, got -0.5", "nap"},
+		{`nap(1e30)`, "nap duration is too long, got 1e+30", "nap"},
+		{`nap(9223372036854775807)`, "nap duration is too long, got 9223372036854775807", "nap"},
+		{`nap(100000000000000000000000)`, "nap duration is too long, got 100000000000000000000000", "nap"},
+		{`purr 1.5 / 0`, "division by zero", "/"},
+		{`hiss (1 + true) { purr "unreachable" }`, "type mismatch: INTEGER + BOOLEAN", "+"},
+		{`scratch (nope) { purr "unreachable" }`, "identifier not found: nope", "nope"},
+		{`purr [1, 2][2]`, "index 2 out of range for array of length 2", "["},
+		{`purr [1, 2][-3]`, "index -3 out of range for array of length 2", "["},
+		{`purr [][0]`, "index 0 out of range for array of length 0", "["},
+		{`purr [1][99999999999999999999]`, "index 99999999999999999999 out of range for array of length 1", "["},
+		{`purr [1, 2]["0"]`, "array index must be an INTEGER, got STRING", "["},
+		{`purr 5[0]`, "index operator not supported: INTEGER", "["},
+		{`lick xs = [1] xs[1] = 2`, "index 1 out of range for array of length 1", "["},
+		{`lick s = "cat" s[0] = "b"`, "index assignment not supported: STRING", "["},
+		{`purr [1, missing]`, "identifier not found: missing", "missing"},
+		{`meow f() { claw } purr {f: 1}`, "unusable as map key: FUNCTION", "f"},
+		{`purr meow(a) { claw a }()`, "wrong number of arguments: want=1, got=0", "("},
+		{`lick f = meow() { claw undefined } purr f()`, "identifier not found: undefined", "undefined"},
+		{`purr {[1]: 1}`, "unusable as map key: ARRAY", "["},
+		{`purr {1.5: 1}`, "unusable as map key: FLOAT", "1.5"},
+		{`purr {1: 1, 2.5: 2}`, "unusable as map key: FLOAT", "2.5"},
+		{`lick m = {} purr m[[1]]`, "unusable as map key: ARRAY", "["},
+		{`meow f() { claw } lick m = {} m[f] = 1`, "unusable as map key: FUNCTION", "["},
+		{`lick m = {} shoo m[{}]`, "unusable as map key: MAP", "["},
+		{`lick s = "cat" shoo s[0]`, "shoo not supported: STRING", "shoo"},
+		{`lick xs = [1] shoo xs[1]`, "index 1 out of range for array of length 1", "["},
+	}
+
+	for _, tt := range tests {
+		output, result := evaluate(t, tt.input)
+
+		err, ok := result.(*object.Error)
+		if !ok {
+			t.Errorf("input %q: expected *object.Error, got %T (%+v)", tt.input, result, result)
+			continue
+		}
+		if err.Message != tt.expectedMessage {
+			t.Errorf("input %q: expected message %q, got %q", tt.input, tt.expectedMessage, err.Message)
+		}
+		if err.Token.Literal != tt.expectedToken {
+			t.Errorf("input %q: expected error at %q, got %q", tt.input, tt.expectedToken, err.Token.Literal)
+		}
+		if output != "" {
+			t.Errorf("input %q: expected no output, got %q", tt.input, output)
+		}
+	}
+}
+
+func TestInterpreter_RuntimeErrorStopsProgram(t *testing.T) {
+	input := `
+    purr "before"
+    purr 1 / 0
+    purr "after"`
+	expectedOutput := "before\n"
+	output, result := evaluate(t, input)
+
+	if output != expectedOutput {
+		t.Errorf("expected output %q, got %q", expectedOutput, output)
+	}
+	if !isError(result) {
+		t.Errorf("expected an error, got %T (%+v)", result, result)
+	}
+}
+
+func TestInterpreter_RuntimeErrorPositions(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"purr foobar", "1:6: identifier not found: foobar"},
+		{"lick a = 1\n\npurr a * 2 - true", "3:12: type mismatch: INTEGER - BOOLEAN"},
+		{"meow half(n) {\n    claw n / 0\n}\npurr half(4)", "2:12: division by zero"},
+		{"purr 1\nclaw 2", "2:1: claw can only be used inside a function"},
+	}
+
+	for _, tt := range tests {
+		_, result := evaluate(t, tt.input)
+
+		err, ok := result.(*object.Error)
+		if !ok {
+			t.Errorf("input %q: expected *object.Error, got %T (%+v)", tt.input, result, result)
+			continue
+		}
+		if err.Error() != tt.expectedError {
+			t.Errorf("input %q: expected error %q, got %q", tt.input, tt.expectedError, err.Error())
+		}
+	}
+}
+
+func TestInterpreter_UnicodeIdentifiers(t *testing.T) {
+	input := `
+    lick chaton = 2
+    lick pattes_par_chat = 4
+    purr chaton * pattes_par_chat
+    purr "🐱 " + "miaou"`
+	expectedOutput := "8\n🐱 miaou\n"
+	output := interpret(t, input)
+
+	if output != expectedOutput {
+		t.Errorf("expected output %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestInterpreter_StringEscapes(t *testing.T) {
+	input := "purr \"\\\"meow\\\"\\tsaid the \\u{1F431}\"\npurr `C:\\cats\\n`"
+	expectedOutput := "\"meow\"\tsaid the 🐱\nC:\\cats\\n\n"
+	output := interpret(t, input)
 
 	if output != expectedOutput {
 		t.Errorf("expected output %q, got %q", expectedOutput, output)
 	}
 }
+
+func TestInterpreter_InterpolatedStrings(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{`lick result = 15 purr "Result of addition: {result}"`, "Result of addition: 15\n"},
+		{`lick a = 2 lick b = 3 purr "{a} + {b} = {a + b}"`, "2 + 3 = 5\n"},
+		{`lick name = "Mochi" purr "Hello, {name}!"`, "Hello, Mochi!\n"},
+		{`purr "is it? {1 < 2}"`, "is it? true\n"},
+		{`meow double(x) { claw x * 2 } purr "double: {double(21)}"`, "double: 42\n"},
+		{`lick cat = "🐱" purr "{"nested {cat}"}"`, "nested 🐱\n"},
+		{`purr "\{not interpolated\}"`, "{not interpolated}\n"},
+	}
+
+	for _, tt := range tests {
+		output := interpret(t, tt.input)
+		if output != tt.expectedOutput {
+			t.Errorf("input %q: expected output %q, got %q", tt.input, tt.expectedOutput, output)
+		}
+	}
+}
+
+func TestInterpreter_InterpolatedStringErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{`purr "value: {missing}"`, "1:15: identifier not found: missing"},
+		{"lick n = 0\npurr \"ratio: {10 / n} 🐱 {n}\"", "2:18: division by zero"},
+		{`purr "🐱🐱 {1 + true}"`, "1:13: type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		_, result := evaluate(t, tt.input)
+
+		err, ok := result.(*object.Error)
+		if !ok {
+			t.Errorf("input %q: expected *object.Error, got %T (%+v)", tt.input, result, result)
+			continue
+		}
+		if err.Error() != tt.expectedError {
+			t.Errorf("input %q: expected error %q, got %q", tt.input, tt.expectedError, err.Error())
+		}
+	}
+}
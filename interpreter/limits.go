@@ -0,0 +1,223 @@
+package interpreter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/object"
+	"github.com/AlyxPink/meowlang/token"
+)
+
+// Limits bounds the resources a program may use, e.g. to run untrusted code.
+// A zero field means no limit, except for MaxCallDepth which defaults to
+// DefaultMaxCallDepth. Steps, output and wall time are counted from the start
+// of each call to InterpretContext or CallContext.
+type Limits struct {
+	MaxSteps       int64         // number of statements and expressions evaluated
+	MaxCallDepth   int           // number of nested function calls, DefaultMaxCallDepth if 0
+	MaxOutputBytes int64         // number of bytes printed
+	MaxDuration    time.Duration // wall time, including naps
+}
+
+// DefaultMaxCallDepth is the call depth limit when Limits.MaxCallDepth is 0,
+// so that runaway recursion is a runtime error rather than a crash of the Go
+// program running it.
+const DefaultMaxCallDepth = 10000
+
+// Limit names one of the Limits.
+type Limit string
+
+const (
+	LimitSteps     Limit = "step"
+	LimitCallDepth Limit = "call depth"
+	LimitOutput    Limit = "output"
+	LimitDuration  Limit = "wall time"
+)
+
+// LimitError is the cause of the runtime error returned when a program exceeds
+// one of its Limits. It can be found with errors.As from the *object.Error.
+type LimitError struct {
+	Limit Limit
+	Max   int64 // the value of the limit, in nanoseconds for LimitDuration
+}
+
+func (e *LimitError) Error() string {
+	switch e.Limit {
+	case LimitOutput:
+		return fmt.Sprintf("%s limit of %d bytes exceeded", e.Limit, e.Max)
+	case LimitDuration:
+		return fmt.Sprintf("%s limit of %s exceeded", e.Limit, time.Duration(e.Max))
+	default:
+		return fmt.Sprintf("%s limit of %d exceeded", e.Limit, e.Max)
+	}
+}
+
+// SetLimits sets the resources the program may use. Only the call depth is limited by default.
+func (rt *Runtime) SetLimits(limits Limits) {
+	rt.limits = limits
+	rt.out.max = limits.MaxOutputBytes
+}
+
+// InterpretContext interprets the given AST node like Interpret. It stops with
+// an error as soon as ctx is done or one of the Limits is exceeded.
+func (i *Interpreter) InterpretContext(ctx context.Context, node ast.Node) object.Object {
+	defer i.rt.Start(ctx)()
+	return i.Interpret(node)
+}
+
+// CallContext calls a function like Call. It stops with an error as soon as
+// ctx is done or one of the Limits is exceeded.
+func (i *Interpreter) CallContext(ctx context.Context, fn object.Object, args ...object.Object) object.Object {
+	defer i.rt.Start(ctx)()
+	return i.Call(fn, args...)
+}
+
+// Start starts counting the steps, output and wall time of a run. The
+// returned function must be called when the run ends.
+//
+// A run started while another one is in progress, e.g. by a Go function
+// calling back into the program, is part of it: it keeps its counters and
+// context, and ctx is ignored.
+func (rt *Runtime) Start(ctx context.Context) (stop func()) {
+	if rt.ctx != nil {
+		return func() {}
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if rt.limits.MaxDuration > 0 {
+		limit := &LimitError{Limit: LimitDuration, Max: int64(rt.limits.MaxDuration)}
+		ctx, cancel = context.WithTimeoutCause(ctx, rt.limits.MaxDuration, limit)
+	}
+
+	rt.ctx = ctx
+	rt.steps = 0
+	rt.out.reset()
+
+	return func() {
+		cancel()
+		rt.ctx = nil
+	}
+}
+
+// Step counts the evaluation of a node. It returns an error if the program
+// must stop there, because of a limit or because its context is done. The
+// error is not located yet, the caller sets its Token to the node.
+func (rt *Runtime) Step() *object.Error {
+	_, err := rt.Steps(1)
+	return err
+}
+
+// Steps counts the evaluation of n nodes, as n calls of Step would, but
+// checks the context once. If the program must stop, it returns the error
+// with the index of the node it stops at.
+func (rt *Runtime) Steps(n int) (int, *object.Error) {
+	counted := n // the nodes evaluated before the step limit is exceeded
+	if limit := rt.limits.MaxSteps; limit > 0 && rt.steps+int64(n) > limit {
+		counted = int(max(limit-rt.steps, 0))
+	}
+
+	if counted > 0 && rt.ctx != nil {
+		select {
+		case <-rt.ctx.Done():
+			rt.steps++
+			return 0, rt.contextError(token.Token{})
+		default:
+		}
+	}
+
+	rt.steps += int64(counted)
+	if counted < n {
+		rt.steps++
+		return counted, newLimitError(token.Token{}, &LimitError{Limit: LimitSteps, Max: rt.limits.MaxSteps})
+	}
+	return n, nil
+}
+
+// CheckCallDepth returns an error located at tok, the '(' of a call, if
+// calling a function with depth calls already in progress exceeds the call depth limit.
+func (rt *Runtime) CheckCallDepth(tok token.Token, depth int) *object.Error {
+	max := rt.limits.MaxCallDepth
+	if max <= 0 {
+		max = DefaultMaxCallDepth
+	}
+	if depth >= max {
+		return newLimitError(tok, &LimitError{Limit: LimitCallDepth, Max: int64(max)})
+	}
+	return nil
+}
+
+// checkContext returns an error located at tok if the context of the run is done.
+func (rt *Runtime) checkContext(tok token.Token) *object.Error {
+	if rt.ctx == nil || rt.ctx.Err() == nil {
+		return nil
+	}
+	return rt.contextError(tok)
+}
+
+// contextError returns an error located at tok, caused by the end of the
+// context of the run, e.g. a *LimitError for the wall time limit.
+func (rt *Runtime) contextError(tok token.Token) *object.Error {
+	err := context.Cause(rt.ctx)
+	return &object.Error{Message: err.Error(), Token: tok, Err: err}
+}
+
+// checkOutput returns an error located at tok if the output limit was exceeded.
+func (rt *Runtime) checkOutput(tok token.Token) *object.Error {
+	if rt.out.exceeded == nil {
+		return nil
+	}
+	return newLimitError(tok, rt.out.exceeded)
+}
+
+func newLimitError(tok token.Token, err *LimitError) *object.Error {
+	return &object.Error{Message: err.Error(), Token: tok, Err: err}
+}
+
+// step counts the evaluation of a node, see Runtime.Step. Its error is located at the node.
+func (i *Interpreter) step(node ast.Node) *object.Error {
+	if err := i.rt.Step(); err != nil {
+		err.Token = nodeToken(node)
+		return err
+	}
+	return nil
+}
+
+// nodeToken returns a token spanning a node, to locate errors.
+func nodeToken(node ast.Node) token.Token {
+	if node == nil {
+		return token.Token{}
+	}
+	return token.Token{Literal: node.TokenLiteral(), Pos: node.Pos(), End: node.End()}
+}
+
+// limitedWriter counts the bytes written to w. Once there would be more than
+// max, it refuses to write anything more.
+type limitedWriter struct {
+	w        io.Writer
+	max      int64 // no limit if 0
+	written  int64
+	exceeded *LimitError
+}
+
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	if lw.exceeded != nil {
+		return 0, lw.exceeded
+	}
+	if lw.max > 0 && lw.written+int64(len(p)) > lw.max {
+		lw.exceeded = &LimitError{Limit: LimitOutput, Max: lw.max}
+		return 0, lw.exceeded
+	}
+
+	n, err := lw.w.Write(p)
+	lw.written += int64(n)
+	return n, err
+}
+
+// reset forgets what was written so far.
+func (lw *limitedWriter) reset() {
+	lw.written = 0
+	lw.exceeded = nil
+}
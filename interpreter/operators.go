@@ -0,0 +1,375 @@
+package interpreter
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/AlyxPink/meowlang/object"
+	"github.com/AlyxPink/meowlang/token"
+)
+
+// The operations below are shared with the vm package, so that both backends
+// agree on the result and the errors of every operator. Their errors are
+// located at tok, the token of the operator.
+
+// Infix applies an infix operator, e.g. + or <, to two values.
+//
+// Operands of different types are only combined by these coercion rules:
+//   - an integer with a float promotes the integer to a float
+//   - string + any, or any + string, concatenates the printed form of the other side
+//   - == and != compare them as different, except null which equals null
+//
+// Any other mix of types is a type mismatch error.
+func Infix(tok token.Token, operator string, left, right object.Object) object.Object {
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return integerInfix(tok, operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return floatInfix(tok, operator, toFloat(left), toFloat(right))
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return stringInfix(tok, operator, left, right)
+	case left.Type() == object.BOOLEAN_OBJ && right.Type() == object.BOOLEAN_OBJ:
+		return booleanInfix(tok, operator, left, right)
+	case operator == "+" && (left.Type() == object.STRING_OBJ || right.Type() == object.STRING_OBJ):
+		return &object.String{Value: left.Inspect() + right.Inspect()}
+	case operator == "==":
+		return nativeBoolToBooleanObject(isSameObject(left, right))
+	case operator == "!=":
+		return nativeBoolToBooleanObject(!isSameObject(left, right))
+	case left.Type() != right.Type():
+		return newError(tok, "type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	}
+
+	return newError(tok, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
+
+// integerInfix applies an infix operator to integer operands.
+// Integers that fit in an int64 are computed directly, falling back to
+// arbitrary precision if either operand or the result does not fit.
+func integerInfix(tok token.Token, operator string, left, right object.Object) object.Object {
+	leftInt := left.(*object.Integer)
+	rightInt := right.(*object.Integer)
+	if leftInt.IsBig() || rightInt.IsBig() {
+		return bigIntegerInfix(tok, operator, leftInt.BigValue(), rightInt.BigValue())
+	}
+
+	leftVal := leftInt.Value
+	rightVal := rightInt.Value
+
+	switch operator {
+	case "+":
+		if value, ok := addInt64(leftVal, rightVal); ok {
+			return object.NewInteger(value)
+		}
+	case "-":
+		if value, ok := subInt64(leftVal, rightVal); ok {
+			return object.NewInteger(value)
+		}
+	case "*":
+		if value, ok := mulInt64(leftVal, rightVal); ok {
+			return object.NewInteger(value)
+		}
+	case "/":
+		if rightVal == 0 {
+			return newError(tok, "division by zero")
+		}
+		if value, ok := quoInt64(leftVal, rightVal); ok {
+			return object.NewInteger(value)
+		}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError(tok, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+
+	// The result overflows an int64
+	return bigIntegerInfix(tok, operator, big.NewInt(leftVal), big.NewInt(rightVal))
+}
+
+// bigIntegerInfix applies an infix operator to arbitrary-precision integer
+// operands. The result is only kept as a big integer if it does not fit in an int64.
+func bigIntegerInfix(tok token.Token, operator string, leftVal, rightVal *big.Int) object.Object {
+	switch operator {
+	case "+":
+		return object.NewBigInteger(leftVal.Add(leftVal, rightVal))
+	case "-":
+		return object.NewBigInteger(leftVal.Sub(leftVal, rightVal))
+	case "*":
+		return object.NewBigInteger(leftVal.Mul(leftVal, rightVal))
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError(tok, "division by zero")
+		}
+		return object.NewBigInteger(leftVal.Quo(leftVal, rightVal))
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) >= 0)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError(tok, "unknown operator: %s %s %s", object.INTEGER_OBJ, operator, object.INTEGER_OBJ)
+	}
+}
+
+// floatInfix applies an infix operator to float operands, integer operands
+// having been promoted to floats.
+func floatInfix(tok token.Token, operator string, leftVal, rightVal float64) object.Object {
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError(tok, "division by zero")
+		}
+		return &object.Float{Value: leftVal / rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError(tok, "unknown operator: %s %s %s", object.FLOAT_OBJ, operator, object.FLOAT_OBJ)
+	}
+}
+
+// stringInfix applies an infix operator to string operands.
+func stringInfix(tok token.Token, operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.String).Value
+	rightVal := right.(*object.String).Value
+
+	switch operator {
+	case "+":
+		return &object.String{Value: leftVal + rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError(tok, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// booleanInfix applies an infix operator to boolean operands.
+func booleanInfix(tok token.Token, operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.Boolean).Value
+	rightVal := right.(*object.Boolean).Value
+
+	switch operator {
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError(tok, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// Prefix applies a prefix operator, - or !, to a value.
+func Prefix(tok token.Token, operator string, right object.Object) object.Object {
+	switch operator {
+	case "!":
+		return nativeBoolToBooleanObject(!IsTruthy(right))
+	case "-":
+		switch right := right.(type) {
+		case *object.Integer:
+			if !right.IsBig() {
+				if value, ok := negInt64(right.Value); ok {
+					return object.NewInteger(value)
+				}
+			}
+			value := right.BigValue()
+			return object.NewBigInteger(value.Neg(value))
+		case *object.Float:
+			return &object.Float{Value: -right.Value}
+		default:
+			return newError(tok, "invalid operand for -: %s", right.Type())
+		}
+	default:
+		return newError(tok, "unknown operator: %s%s", operator, right.Type())
+	}
+}
+
+// Index returns an element of a collection, e.g. xs[0]. A key that is not in
+// a map gives null.
+func Index(tok token.Token, left, index object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		position, err := arrayPosition(tok, left, index)
+		if err != nil {
+			return err
+		}
+		return left.Elements[position]
+	case *object.Map:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError(tok, "unusable as map key: %s", index.Type())
+		}
+		value, ok := left.Get(key)
+		if !ok {
+			return &object.Null{}
+		}
+		return value
+	default:
+		return newError(tok, "index operator not supported: %s", left.Type())
+	}
+}
+
+// SetIndex assigns an element of a collection, e.g. xs[0] = 1, and returns the value.
+// The collection is updated in place, so every variable holding it sees the change.
+func SetIndex(tok token.Token, left, index, val object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		position, err := arrayPosition(tok, left, index)
+		if err != nil {
+			return err
+		}
+		left.Elements[position] = val
+		return val
+	case *object.Map:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError(tok, "unusable as map key: %s", index.Type())
+		}
+		left.Set(key, val)
+		return val
+	default:
+		return newError(tok, "index assignment not supported: %s", left.Type())
+	}
+}
+
+// Delete removes an element from a collection for the 'shoo' statement at
+// shoo, whose target has its '[' at bracket. Removing a key that is not in a
+// map does nothing.
+func Delete(shoo, bracket token.Token, left, index object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		position, err := arrayPosition(bracket, left, index)
+		if err != nil {
+			return err
+		}
+		left.Elements = append(left.Elements[:position], left.Elements[position+1:]...)
+	case *object.Map:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError(bracket, "unusable as map key: %s", index.Type())
+		}
+		left.Delete(key)
+	default:
+		return newError(shoo, "shoo not supported: %s", left.Type())
+	}
+
+	return &object.Null{}
+}
+
+// arrayPosition returns the position in an array of an index. Negative indices
+// count from the end, e.g. -1 is the last element.
+func arrayPosition(tok token.Token, array *object.Array, index object.Object) (int, *object.Error) {
+	integer, ok := index.(*object.Integer)
+	if !ok {
+		return 0, newError(tok, "array index must be an INTEGER, got %s", index.Type())
+	}
+
+	length := int64(len(array.Elements))
+	position := integer.Value
+	if position < 0 {
+		position += length
+	}
+	if integer.IsBig() || position < 0 || position >= length {
+		return 0, newError(tok, "index %s out of range for array of length %d", integer.Inspect(), length)
+	}
+
+	return int(position), nil
+}
+
+// IsTruthy reports whether an object counts as true in a condition.
+// null, false, 0, 0.0 and "" are falsy, everything else is truthy.
+func IsTruthy(obj object.Object) bool {
+	switch obj := obj.(type) {
+	case nil, *object.Null:
+		return false
+	case *object.Boolean:
+		return obj.Value
+	case *object.Integer:
+		return obj.IsBig() || obj.Value != 0
+	case *object.Float:
+		return obj.Value != 0
+	case *object.String:
+		return obj.Value != ""
+	default:
+		return true
+	}
+}
+
+// isNumber reports whether an object is an integer or a float.
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+// toFloat converts a number object to a Go float64.
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		if obj.IsBig() {
+			value, _ := new(big.Float).SetInt(obj.Big).Float64()
+			return value
+		}
+		return float64(obj.Value)
+	case *object.Float:
+		return obj.Value
+	default:
+		return math.NaN()
+	}
+}
+
+// nativeBoolToBooleanObject converts a Go bool to the shared Boolean objects.
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return TRUE
+	}
+	return FALSE
+}
+
+// isSameObject is the equality used between objects without a value comparison,
+// such as mismatched types: all nulls are equal, anything else only equals itself.
+func isSameObject(left, right object.Object) bool {
+	if left.Type() == object.NULL_OBJ && right.Type() == object.NULL_OBJ {
+		return true
+	}
+	return left == right
+}
@@ -0,0 +1,170 @@
+package interpreter
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AlyxPink/meowlang/object"
+	"github.com/AlyxPink/meowlang/token"
+)
+
+// Runtime is what a running program shares with the Go program running it:
+// its global variables, its input and output, how it naps and its limits.
+// Interpreter and vm.VM both run programs on a Runtime, so that they print,
+// nap, call built-in functions and enforce limits alike.
+//
+// It also gives built-in functions access to the input and output, as an object.Runtime.
+type Runtime struct {
+	globals *object.Environment
+	out     *limitedWriter // where 'purr' prints
+	errOut  io.Writer      // where diagnostics are written
+	in      *bufio.Reader
+	reading chan lineRead // the line being read from in, if a read was interrupted
+	sleeper Sleeper
+	napUnit time.Duration
+
+	ctx    context.Context // the context of the current run, if any
+	limits Limits
+	steps  int64 // the number of steps of the current run
+}
+
+// NewRuntime creates a Runtime printing to stdout, as the program runs, and
+// writing diagnostics to stderr. The 'input' built-in function reads os.Stdin.
+func NewRuntime(stdout, stderr io.Writer) *Runtime {
+	rt := NewRuntimeWithEnv(newGlobalEnvironment())
+	rt.out = &limitedWriter{w: stdout}
+	rt.errOut = stderr
+	rt.in = bufio.NewReader(os.Stdin)
+	return rt
+}
+
+// NewRuntimeWithEnv creates a Runtime with a specified global environment, discarding what the program prints.
+// The environment must enclose one created by NewRuntime for built-in functions to be available.
+func NewRuntimeWithEnv(env *object.Environment) *Runtime {
+	return &Runtime{globals: env, out: &limitedWriter{w: io.Discard}, errOut: io.Discard, sleeper: realSleeper, napUnit: DefaultNapUnit}
+}
+
+// Env returns the environment the program runs in, e.g. to define variables before running it.
+func (rt *Runtime) Env() *object.Environment {
+	return rt.globals
+}
+
+// SetInput sets where the 'input' built-in function reads from, os.Stdin by default.
+func (rt *Runtime) SetInput(in io.Reader) {
+	rt.in = bufio.NewReader(in)
+	rt.reading = nil
+}
+
+// SetSleeper replaces the Sleeper used by 'nap', so tests can run without actually sleeping.
+func (rt *Runtime) SetSleeper(sleeper Sleeper) {
+	rt.sleeper = sleeper
+}
+
+// SetNapUnit sets the duration of one unit of time passed to 'nap', e.g. time.Millisecond.
+func (rt *Runtime) SetNapUnit(unit time.Duration) {
+	rt.napUnit = unit
+}
+
+// Print prints a value for the 'purr' statement at tok.
+func (rt *Runtime) Print(tok token.Token, val object.Object) *object.Error {
+	if _, err := fmt.Fprintln(rt.out, val.Inspect()); err != nil {
+		if limitErr := rt.checkOutput(tok); limitErr != nil {
+			return limitErr
+		}
+		return &object.Error{Message: "cannot print: " + err.Error(), Token: tok, Err: err}
+	}
+	return nil
+}
+
+// CallBuiltin calls a built-in function. Its errors are located at tok, the
+// '(' of the call.
+func (rt *Runtime) CallBuiltin(tok token.Token, builtin *object.Builtin, args []object.Object) object.Object {
+	if rt.in == nil {
+		rt.in = bufio.NewReader(os.Stdin)
+	}
+
+	result := builtin.Fn(rt, args...)
+	if err := rt.checkOutput(tok); err != nil {
+		return err
+	}
+	if err := rt.checkContext(tok); err != nil {
+		return err
+	}
+	if err, ok := result.(*object.Error); ok && !err.Token.Pos.IsValid() {
+		err.Token = tok
+	}
+	return result
+}
+
+// Write prints p to the output of the program.
+func (rt *Runtime) Write(p []byte) (int, error) {
+	return rt.out.Write(p)
+}
+
+// Stderr returns where diagnostics are written.
+func (rt *Runtime) Stderr() io.Writer {
+	return rt.errOut
+}
+
+// ReadLine reads a line of input, without its "\n" or "\r\n" ending. The last
+// line is returned even if it has no line ending.
+//
+// During a run with a context, it stops waiting for the line as soon as the
+// context is done, e.g. when the wall time limit is exceeded while the input
+// stays open. The line is then returned by the next ReadLine.
+func (rt *Runtime) ReadLine() (string, error) {
+	if rt.ctx == nil && rt.reading == nil {
+		return readLine(rt.in)
+	}
+
+	if rt.reading == nil {
+		reading, in := make(chan lineRead, 1), rt.in
+		go func() {
+			line, err := readLine(in)
+			reading <- lineRead{line, err}
+		}()
+		rt.reading = reading
+	}
+
+	var done <-chan struct{}
+	if rt.ctx != nil {
+		done = rt.ctx.Done()
+	}
+	select {
+	case read := <-rt.reading:
+		rt.reading = nil
+		return read.line, read.err
+	case <-done:
+		return "", context.Cause(rt.ctx)
+	}
+}
+
+// lineRead is the result of readLine.
+type lineRead struct {
+	line string
+	err  error
+}
+
+func readLine(in *bufio.Reader) (string, error) {
+	line, err := in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
+
+// newGlobalEnvironment creates the environment of a program. The built-in
+// functions are defined in an enclosing scope, so that programs can reuse
+// their names for their own variables.
+func newGlobalEnvironment() *object.Environment {
+	builtins := object.NewEnvironment()
+	for _, builtin := range object.Builtins {
+		builtins.Set(builtin.Name, builtin)
+	}
+	return object.NewEnclosedEnvironment(builtins)
+}
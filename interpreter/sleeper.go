@@ -0,0 +1,78 @@
+package interpreter
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/AlyxPink/meowlang/object"
+	"github.com/AlyxPink/meowlang/token"
+)
+
+// DefaultNapUnit is the duration of one unit of time passed to 'nap'.
+const DefaultNapUnit = time.Second
+
+// Sleeper pauses the execution of a program, it is called by 'nap'.
+type Sleeper interface {
+	Sleep(d time.Duration)
+}
+
+// SleeperFunc adapts an ordinary function to the Sleeper interface.
+type SleeperFunc func(d time.Duration)
+
+// Sleep calls f(d).
+func (f SleeperFunc) Sleep(d time.Duration) {
+	f(d)
+}
+
+// contextSleeper is a Sleeper that wakes up early when a context is done, so
+// that a canceled program does not finish its nap.
+type contextSleeper interface {
+	SleepContext(ctx context.Context, d time.Duration)
+}
+
+// clockSleeper sleeps using the system clock.
+type clockSleeper struct{}
+
+func (clockSleeper) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (clockSleeper) SleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// realSleeper sleeps using the system clock.
+var realSleeper Sleeper = clockSleeper{}
+
+// Nap pauses the program for the 'nap' statement at tok, for val nap units.
+// It wakes up early, with an error, if the context of the run is done.
+func (rt *Runtime) Nap(tok token.Token, val object.Object) *object.Error {
+	var units float64
+	switch val := val.(type) {
+	case *object.Integer, *object.Float:
+		units = toFloat(val)
+	default:
+		return newError(tok, "nap duration must be a number, got %s", val.Type())
+	}
+	if units < 0 || math.IsNaN(units) {
+		return newError(tok, "nap duration must not be negative, got %s", val.Inspect())
+	}
+	if units >= math.MaxInt64/float64(rt.napUnit) {
+		return newError(tok, "nap duration is too long, got %s", val.Inspect())
+	}
+
+	duration := time.Duration(units * float64(rt.napUnit))
+	if sleeper, ok := rt.sleeper.(contextSleeper); ok && rt.ctx != nil {
+		sleeper.SleepContext(rt.ctx, duration)
+	} else {
+		rt.sleeper.Sleep(duration)
+	}
+	return rt.checkContext(tok)
+}
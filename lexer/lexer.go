@@ -1,129 +1,425 @@
 package lexer
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/AlyxPink/meowlang/token"
 )
 
+// Lexer turns UTF-8 encoded source code into tokens. Offsets are counted in
+// bytes, while columns are counted in runes.
 type Lexer struct {
 	input        string
-	position     int
-	readPosition int
-	ch           byte
+	position     int  // byte offset of ch
+	readPosition int  // byte offset of the rune after ch
+	ch           rune // current rune, 0 at the end of the input
+	line         int  // line of ch
+	column       int  // column of ch
+	errors       []string
+
+	// interpolations holds the string interpolations being lexed, innermost last
+	interpolations []interpolation
+}
+
+// interpolation tracks an embedded expression of an interpolated string.
+type interpolation struct {
+	start token.Position // position of the opening '"' of the string
+	depth int            // number of '{' opened inside the embedded expression
 }
 
 func NewLexer(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 	return l
 }
 
+// Errors returns the errors encountered while reading the input, such as
+// unterminated strings. The lexer still returns a best-effort token for them.
+func (l *Lexer) Errors() []string {
+	return l.errors
+}
+
+// Tokenize returns all the tokens of the input, ending with a token.EOF token.
 func (l *Lexer) Tokenize() []token.Token {
 	var tokens []token.Token
-	for l.ch != 0 {
-		switch l.ch {
-		case '=':
-			tokens = append(tokens, token.Token{Type: token.ASSIGN, Literal: string(l.ch)})
-		case '+':
-			tokens = append(tokens, token.Token{Type: token.PLUS, Literal: string(l.ch)})
-		case '-':
-			tokens = append(tokens, token.Token{Type: token.MINUS, Literal: string(l.ch)})
-		case '*':
-			tokens = append(tokens, token.Token{Type: token.ASTERISK, Literal: string(l.ch)})
-		case ';':
-			tokens = append(tokens, token.Token{Type: token.SEMICOLON, Literal: string(l.ch)})
-		case '(':
-			tokens = append(tokens, token.Token{Type: token.LPAREN, Literal: string(l.ch)})
-		case ')':
-			tokens = append(tokens, token.Token{Type: token.RPAREN, Literal: string(l.ch)})
-		case '{':
-			tokens = append(tokens, token.Token{Type: token.LBRACE, Literal: string(l.ch)})
-		case '}':
-			tokens = append(tokens, token.Token{Type: token.RBRACE, Literal: string(l.ch)})
-		case '>':
-			tokens = append(tokens, token.Token{Type: token.GT, Literal: string(l.ch)})
-		case '<':
-			tokens = append(tokens, token.Token{Type: token.LT, Literal: string(l.ch)})
-		case ',':
-			tokens = append(tokens, token.Token{Type: token.COMMA, Literal: string(l.ch)})
-		case '"':
-			tokens = append(tokens, token.Token{Type: token.STRING, Literal: l.readString()})
-		case '/': // Comment or division operator
-			if l.peekChar() == '/' {
-				l.skipSingleLineComment()
-			} else if l.peekChar() == '*' {
-				l.skipBlockComment()
-			} else {
-				tokens = append(tokens, token.Token{Type: token.SLASH, Literal: string(l.ch)})
-			}
-		default:
-			if isSpace(l.ch) {
-				l.readChar()
-				continue
-			} else if isLetter(l.ch) {
-				literal := l.readIdentifier()
-				tokens = append(tokens, token.Token{Type: token.LookupIdent(literal), Literal: literal})
-				continue
-			} else if isDigit(l.ch) {
-				tokens = append(tokens, token.Token{Type: token.INT, Literal: l.readNumber()})
-				continue
-			} else {
-				tokens = append(tokens, token.Token{Type: token.ILLEGAL, Literal: string(l.ch)})
+	for {
+		tok := l.NextToken()
+		tokens = append(tokens, tok)
+		if tok.Type == token.EOF {
+			return tokens
+		}
+	}
+}
+
+// NextToken skips whitespace and comments, and returns the next token of the input.
+func (l *Lexer) NextToken() token.Token {
+	l.skipWhitespaceAndComments()
+
+	var tok token.Token
+	start := l.currentPosition()
+
+	switch l.ch {
+	case 0:
+		for _, interp := range l.interpolations {
+			l.errorAt(interp.start, "unterminated string interpolation")
+		}
+		l.interpolations = nil
+		return token.Token{Type: token.EOF, Literal: "", Pos: start, End: start}
+	case '=':
+		if l.peekChar() == '=' {
+			l.readChar() // consume the first character of the operator
+			tok = token.Token{Type: token.EQ, Literal: "=="}
+		} else {
+			tok = token.Token{Type: token.ASSIGN, Literal: "="}
+		}
+	case '!':
+		if l.peekChar() == '=' {
+			l.readChar() // consume the first character of the operator
+			tok = token.Token{Type: token.NOT_EQ, Literal: "!="}
+		} else {
+			tok = token.Token{Type: token.BANG, Literal: "!"}
+		}
+	case '+':
+		tok = token.Token{Type: token.PLUS, Literal: string(l.ch)}
+	case '-':
+		tok = token.Token{Type: token.MINUS, Literal: string(l.ch)}
+	case '*':
+		tok = token.Token{Type: token.ASTERISK, Literal: string(l.ch)}
+	case '/':
+		tok = token.Token{Type: token.SLASH, Literal: string(l.ch)}
+	case ';':
+		tok = token.Token{Type: token.SEMICOLON, Literal: string(l.ch)}
+	case '(':
+		tok = token.Token{Type: token.LPAREN, Literal: string(l.ch)}
+	case ')':
+		tok = token.Token{Type: token.RPAREN, Literal: string(l.ch)}
+	case '{':
+		if n := len(l.interpolations); n > 0 {
+			l.interpolations[n-1].depth++
+		}
+		tok = token.Token{Type: token.LBRACE, Literal: string(l.ch)}
+	case '}':
+		if n := len(l.interpolations); n > 0 {
+			if l.interpolations[n-1].depth == 0 {
+				// End of the embedded expression, the string goes on
+				tok = l.readStringContinuation()
+				tok.Pos, tok.End = start, l.currentPosition()
+				return tok
 			}
+			l.interpolations[n-1].depth--
+		}
+		tok = token.Token{Type: token.RBRACE, Literal: string(l.ch)}
+	case '>':
+		if l.peekChar() == '=' {
+			l.readChar() // consume the first character of the operator
+			tok = token.Token{Type: token.GT_EQ, Literal: ">="}
+		} else {
+			tok = token.Token{Type: token.GT, Literal: ">"}
+		}
+	case '<':
+		if l.peekChar() == '=' {
+			l.readChar() // consume the first character of the operator
+			tok = token.Token{Type: token.LT_EQ, Literal: "<="}
+		} else {
+			tok = token.Token{Type: token.LT, Literal: "<"}
+		}
+	case ',':
+		tok = token.Token{Type: token.COMMA, Literal: string(l.ch)}
+	case ':':
+		tok = token.Token{Type: token.COLON, Literal: string(l.ch)}
+	case '[':
+		tok = token.Token{Type: token.LBRACKET, Literal: string(l.ch)}
+	case ']':
+		tok = token.Token{Type: token.RBRACKET, Literal: string(l.ch)}
+	case '"':
+		tok = l.readString()
+		tok.Pos, tok.End = start, l.currentPosition()
+		return tok
+	case '`':
+		tok = token.Token{Type: token.STRING, Literal: l.readRawString()}
+		tok.Pos, tok.End = start, l.currentPosition()
+		return tok
+	default:
+		if isLetter(l.ch) {
+			literal := l.readIdentifier()
+			tok = token.Token{Type: token.LookupIdent(literal), Literal: literal}
+			tok.Pos, tok.End = start, l.currentPosition()
+			return tok
+		} else if isDigit(l.ch) {
+			tok = l.readNumber()
+			tok.Pos, tok.End = start, l.currentPosition()
+			return tok
+		} else {
+			tok = token.Token{Type: token.ILLEGAL, Literal: string(l.ch)}
 		}
-		l.readChar()
 	}
-	tokens = append(tokens, token.Token{Type: token.EOF, Literal: ""})
-	return tokens
+
+	l.readChar() // consume the last character of the token
+	tok.Pos, tok.End = start, l.currentPosition()
+	return tok
 }
 
+// readChar advances to the next rune, keeping track of lines and columns.
 func (l *Lexer) readChar() {
-	l.peekChar()
-	l.readPosition++
-}
+	if l.readPosition > len(l.input) {
+		return // already at the end of the input
+	}
 
-func (l *Lexer) peekChar() byte {
-	if l.readPosition >= len(l.input) {
-		l.ch = 0
+	if l.ch == '\n' {
+		l.line++
+		l.column = 1
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.column++
 	}
+
 	l.position = l.readPosition
-	return l.ch
+	if l.position >= len(l.input) {
+		l.ch = 0
+		l.readPosition = l.position + 1
+		return
+	}
+
+	ch, width := utf8.DecodeRuneInString(l.input[l.position:])
+	l.ch = ch
+	l.readPosition = l.position + width
 }
 
+// peekChar returns the rune after the current one without advancing.
+func (l *Lexer) peekChar() rune {
+	if l.readPosition >= len(l.input) {
+		return 0
+	}
+	ch, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return ch
+}
+
+// currentPosition returns the position of the current character.
+func (l *Lexer) currentPosition() token.Position {
+	return token.Position{Offset: l.position, Line: l.line, Column: l.column}
+}
+
+// readIdentifier reads an identifier: a letter followed by letters and digits.
 func (l *Lexer) readIdentifier() string {
 	position := l.position
-	for isLetter(l.ch) {
+	for isLetter(l.ch) || unicode.IsDigit(l.ch) {
 		l.readChar()
 	}
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readNumber() string {
-	position := l.position
+// readNumber reads a number literal. It is a token.INT made of digits, or a
+// token.FLOAT if a fraction, e.g. 1.5, or an exponent, e.g. 1e3 or 2.5E-3, follows.
+func (l *Lexer) readNumber() token.Token {
+	start := l.currentPosition()
+	tokenType := token.INT
+
+	l.readDigits()
+
+	// A '.' only starts a fraction if a digit follows it
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
+		l.readChar() // consume '.'
+		l.readDigits()
+	}
+
+	if l.ch == 'e' || l.ch == 'E' {
+		tokenType = token.FLOAT
+		l.readChar() // consume 'e'
+		if l.ch == '+' || l.ch == '-' {
+			l.readChar()
+		}
+		if !isDigit(l.ch) {
+			l.errorAt(start, "exponent has no digits in number literal "+l.input[start.Offset:l.position])
+		}
+		l.readDigits()
+	}
+
+	return token.Token{Type: tokenType, Literal: l.input[start.Offset:l.position]}
+}
+
+// readDigits reads a sequence of decimal digits.
+func (l *Lexer) readDigits() {
 	for isDigit(l.ch) {
 		l.readChar()
 	}
-	return l.input[position:l.position]
 }
 
-func (l *Lexer) readString() string {
-	var s []byte
-	l.readChar() // consume '"'
-	for {
-		if l.ch == '"' {
-			l.readChar() // consume '"' or 0
-			break
+// readString reads a string literal, starting at its opening '"'. Escape
+// sequences are replaced in its content. It must end on the line it starts,
+// raw string literals are used for multi-line strings.
+//
+// If the string embeds an expression, e.g. "Result: {result}", only the part
+// up to the '{' is read and returned as a token.TEMPLATE_HEAD. The tokens of
+// the expression follow, then the rest of the string, see readStringContinuation.
+func (l *Lexer) readString() token.Token {
+	start := l.currentPosition()
+	l.readChar() // consume opening '"'
+
+	content, interpolated := l.readStringPart(start)
+	if interpolated {
+		return token.Token{Type: token.TEMPLATE_HEAD, Literal: content}
+	}
+	return token.Token{Type: token.STRING, Literal: content}
+}
+
+// readStringContinuation reads the rest of an interpolated string, starting
+// at the '}' closing an embedded expression. It returns a token.TEMPLATE_MIDDLE
+// if another expression is embedded, or a token.TEMPLATE_TAIL.
+func (l *Lexer) readStringContinuation() token.Token {
+	interp := l.interpolations[len(l.interpolations)-1]
+	l.interpolations = l.interpolations[:len(l.interpolations)-1]
+	l.readChar() // consume '}'
+
+	content, interpolated := l.readStringPart(interp.start)
+	if interpolated {
+		return token.Token{Type: token.TEMPLATE_MIDDLE, Literal: content}
+	}
+	return token.Token{Type: token.TEMPLATE_TAIL, Literal: content}
+}
+
+// readStringPart reads the content of a string up to its closing '"', which
+// is consumed, or up to a '{' starting an embedded expression, in which case
+// it reports true. Literal braces are written '\{' and '\}'.
+func (l *Lexer) readStringPart(start token.Position) (string, bool) {
+	var out strings.Builder
+
+	for l.ch != '"' {
+		switch l.ch {
+		case 0, '\n':
+			l.errorAt(start, "unterminated string literal")
+			return out.String(), false
+		case '{':
+			if l.isEmptyInterpolation() {
+				l.errorAt(l.currentPosition(), "empty interpolation")
+				for l.ch != '}' {
+					l.readChar()
+				}
+				l.readChar() // consume '}', the string goes on
+				continue
+			}
+			l.readChar() // consume '{'
+			l.interpolations = append(l.interpolations, interpolation{start: start})
+			return out.String(), true
+		case '\\':
+			l.readEscape(&out)
+		default:
+			out.WriteRune(l.ch)
+			l.readChar()
 		}
-		s = append(s, l.ch) // consume character and add to string
+	}
+	l.readChar() // consume closing '"'
+
+	return out.String(), false
+}
+
+// isEmptyInterpolation reports whether the '{' at ch embeds no expression,
+// e.g. "{}" or "{ }".
+func (l *Lexer) isEmptyInterpolation() bool {
+	return strings.HasPrefix(strings.TrimLeft(l.input[l.readPosition:], " \t"), "}")
+}
+
+// readEscape reads an escape sequence, e.g. '\n' or '\u{1F431}', and writes
+// the character it stands for. Invalid escapes are reported and kept as is.
+func (l *Lexer) readEscape(out *strings.Builder) {
+	start := l.currentPosition()
+	l.readChar() // consume '\'
+
+	switch l.ch {
+	case 'n':
+		out.WriteRune('\n')
+	case 't':
+		out.WriteRune('\t')
+	case 'r':
+		out.WriteRune('\r')
+	case '0':
+		out.WriteRune(0)
+	case '\\', '"', '{', '}':
+		out.WriteRune(l.ch)
+	case 'u':
+		out.WriteString(l.readUnicodeEscape(start))
+		return
+	case 0, '\n':
+		return // reported as an unterminated string
+	default:
+		l.errorAt(start, fmt.Sprintf("invalid escape sequence '\\%c'", l.ch))
+		out.WriteRune('\\')
+		out.WriteRune(l.ch)
+	}
+	l.readChar() // consume the escaped character
+}
+
+// readUnicodeEscape reads the '{1F431}' part of a '\u{1F431}' escape sequence,
+// the current character being the 'u'.
+func (l *Lexer) readUnicodeEscape(start token.Position) string {
+	l.readChar() // consume 'u'
+	if l.ch != '{' {
+		l.errorAt(start, "invalid unicode escape sequence, expected '\\u{...}'")
+		return "\\u"
+	}
+	l.readChar() // consume '{'
+
+	position := l.position
+	for l.ch != '}' && l.ch != '"' && l.ch != '\n' && l.ch != 0 {
 		l.readChar()
-		if l.ch == 0 { // EOF
-			break
+	}
+	digits := l.input[position:l.position]
+	if l.ch != '}' {
+		l.errorAt(start, "invalid unicode escape sequence, expected '\\u{...}'")
+		return "\\u{" + digits
+	}
+	l.readChar() // consume '}'
+
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || len(digits) > 6 || !utf8.ValidRune(rune(code)) {
+		l.errorAt(start, fmt.Sprintf("invalid unicode code point '\\u{%s}'", digits))
+		return "\\u{" + digits + "}"
+	}
+
+	return string(rune(code))
+}
+
+// readRawString reads a raw string literal enclosed in backticks. Its content
+// is kept as is: there are no escape sequences and it may span multiple lines.
+func (l *Lexer) readRawString() string {
+	start := l.currentPosition()
+
+	l.readChar() // consume opening '`'
+	position := l.position
+	for l.ch != '`' {
+		if l.ch == 0 {
+			l.errorAt(start, "unterminated raw string literal")
+			return l.input[position:l.position]
+		}
+		l.readChar()
+	}
+	s := l.input[position:l.position]
+	l.readChar() // consume closing '`'
+
+	return s
+}
+
+// errorAt records an error located at the given position, e.g. "3:7: unterminated string literal".
+func (l *Lexer) errorAt(pos token.Position, msg string) {
+	l.errors = append(l.errors, pos.String()+": "+msg)
+}
+
+func (l *Lexer) skipWhitespaceAndComments() {
+	for {
+		switch {
+		case isSpace(l.ch):
+			l.readChar()
+		case l.ch == '/' && l.peekChar() == '/':
+			l.skipSingleLineComment()
+		case l.ch == '/' && l.peekChar() == '*':
+			l.skipBlockComment()
+		default:
+			return
 		}
 	}
-	return string(s)
 }
 
 func (l *Lexer) skipSingleLineComment() {
@@ -133,28 +429,29 @@ func (l *Lexer) skipSingleLineComment() {
 }
 
 func (l *Lexer) skipBlockComment() {
+	l.readChar() // consume '/'
 	l.readChar() // consume '*'
-	for {
+	for l.ch != 0 {
 		if l.ch == '*' && l.peekChar() == '/' {
 			l.readChar() // consume '*'
 			l.readChar() // consume '/'
 			break
 		}
 		l.readChar()
-		if l.ch == 0 {
-			break
-		}
 	}
 }
 
-func isSpace(ch byte) bool {
-	return unicode.IsSpace(rune(ch))
+func isSpace(ch rune) bool {
+	return unicode.IsSpace(ch)
 }
 
-func isLetter(ch byte) bool {
-	return unicode.IsLetter(rune(ch))
+// isLetter reports whether a rune can start an identifier, following the
+// Unicode letter rules. '_' counts as a letter.
+func isLetter(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
 }
 
-func isDigit(ch byte) bool {
-	return unicode.IsDigit(rune(ch))
+// isDigit reports whether a rune is a decimal digit of a number literal.
+func isDigit(ch rune) bool {
+	return '0' <= ch && ch <= '9'
 }
@@ -198,3 +198,435 @@ func compareTokens(t *testing.T, tokens []token.Token, tests []struct {
 		}
 	}
 }
+
+func TestStringFollowedByDelimiter(t *testing.T) {
+	input := `hiss ("meow") {}`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.HISS, "hiss"}, {token.LPAREN, "("}, {token.STRING, "meow"}, {token.RPAREN, ")"}, {token.LBRACE, "{"}, {token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+
+	l := NewLexer(input)
+	tokens := l.Tokenize()
+
+	compareTokens(t, tokens, tests)
+}
+
+func TestComparisonOperators(t *testing.T) {
+	input := `a == b != c < d > e <= f >= g !h = true false`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"}, {token.EQ, "=="}, {token.IDENT, "b"}, {token.NOT_EQ, "!="}, {token.IDENT, "c"},
+		{token.LT, "<"}, {token.IDENT, "d"}, {token.GT, ">"}, {token.IDENT, "e"},
+		{token.LT_EQ, "<="}, {token.IDENT, "f"}, {token.GT_EQ, ">="}, {token.IDENT, "g"},
+		{token.BANG, "!"}, {token.IDENT, "h"}, {token.ASSIGN, "="}, {token.TRUE, "true"}, {token.FALSE, "false"},
+		{token.EOF, ""},
+	}
+
+	l := NewLexer(input)
+	tokens := l.Tokenize()
+
+	compareTokens(t, tokens, tests)
+}
+
+func TestCommentBoundaries(t *testing.T) {
+	input := `/*/ still a comment */purr 6 / 3// trailing`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.PURR, "purr"}, {token.INT, "6"}, {token.SLASH, "/"}, {token.INT, "3"},
+		{token.EOF, ""},
+	}
+
+	l := NewLexer(input)
+	tokens := l.Tokenize()
+
+	compareTokens(t, tokens, tests)
+}
+
+func TestTokenPositions(t *testing.T) {
+	input := "lick x = 5\n/* a block\n   comment */ purr `multi\nline` + x\n"
+
+	tests := []struct {
+		expectedType token.TokenType
+		expectedPos  token.Position
+		expectedEnd  token.Position
+	}{
+		{token.LICK, token.Position{Offset: 0, Line: 1, Column: 1}, token.Position{Offset: 4, Line: 1, Column: 5}},
+		{token.IDENT, token.Position{Offset: 5, Line: 1, Column: 6}, token.Position{Offset: 6, Line: 1, Column: 7}},
+		{token.ASSIGN, token.Position{Offset: 7, Line: 1, Column: 8}, token.Position{Offset: 8, Line: 1, Column: 9}},
+		{token.INT, token.Position{Offset: 9, Line: 1, Column: 10}, token.Position{Offset: 10, Line: 1, Column: 11}},
+		{token.PURR, token.Position{Offset: 36, Line: 3, Column: 15}, token.Position{Offset: 40, Line: 3, Column: 19}},
+		{token.STRING, token.Position{Offset: 41, Line: 3, Column: 20}, token.Position{Offset: 53, Line: 4, Column: 6}},
+		{token.PLUS, token.Position{Offset: 54, Line: 4, Column: 7}, token.Position{Offset: 55, Line: 4, Column: 8}},
+		{token.IDENT, token.Position{Offset: 56, Line: 4, Column: 9}, token.Position{Offset: 57, Line: 4, Column: 10}},
+		{token.EOF, token.Position{Offset: 58, Line: 5, Column: 1}, token.Position{Offset: 58, Line: 5, Column: 1}},
+	}
+
+	l := NewLexer(input)
+	tokens := l.Tokenize()
+
+	if len(tokens) != len(tests) {
+		t.Fatalf("wrong number of tokens. expected=%d, got=%d", len(tests), len(tokens))
+	}
+
+	for i, tt := range tests {
+		tok := tokens[i]
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Pos != tt.expectedPos {
+			t.Errorf("tests[%d] - pos wrong. expected=%+v, got=%+v", i, tt.expectedPos, tok.Pos)
+		}
+
+		if tok.End != tt.expectedEnd {
+			t.Errorf("tests[%d] - end wrong. expected=%+v, got=%+v", i, tt.expectedEnd, tok.End)
+		}
+	}
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	input := `lick chaton = 1
+lick café_2 = "🐱 miaou"
+purr 猫 + _x9`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LICK, "lick"}, {token.IDENT, "chaton"}, {token.ASSIGN, "="}, {token.INT, "1"},
+		{token.LICK, "lick"}, {token.IDENT, "café_2"}, {token.ASSIGN, "="}, {token.STRING, "🐱 miaou"},
+		{token.PURR, "purr"}, {token.IDENT, "猫"}, {token.PLUS, "+"}, {token.IDENT, "_x9"},
+		{token.EOF, ""},
+	}
+
+	l := NewLexer(input)
+	tokens := l.Tokenize()
+
+	compareTokens(t, tokens, tests)
+}
+
+func TestIllegalRunes(t *testing.T) {
+	input := "lick 🐱 = 1 \xff"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LICK, "lick"}, {token.ILLEGAL, "🐱"}, {token.ASSIGN, "="}, {token.INT, "1"}, {token.ILLEGAL, "�"},
+		{token.EOF, ""},
+	}
+
+	l := NewLexer(input)
+	tokens := l.Tokenize()
+
+	compareTokens(t, tokens, tests)
+}
+
+func TestUnicodeColumns(t *testing.T) {
+	input := "purr \"🐱🐾\" + café\n  nap(é)"
+
+	tests := []struct {
+		expectedLiteral string
+		expectedPos     token.Position
+		expectedEnd     token.Position
+	}{
+		{"purr", token.Position{Offset: 0, Line: 1, Column: 1}, token.Position{Offset: 4, Line: 1, Column: 5}},
+		{"🐱🐾", token.Position{Offset: 5, Line: 1, Column: 6}, token.Position{Offset: 15, Line: 1, Column: 10}},
+		{"+", token.Position{Offset: 16, Line: 1, Column: 11}, token.Position{Offset: 17, Line: 1, Column: 12}},
+		{"café", token.Position{Offset: 18, Line: 1, Column: 13}, token.Position{Offset: 23, Line: 1, Column: 17}},
+		{"nap", token.Position{Offset: 26, Line: 2, Column: 3}, token.Position{Offset: 29, Line: 2, Column: 6}},
+		{"(", token.Position{Offset: 29, Line: 2, Column: 6}, token.Position{Offset: 30, Line: 2, Column: 7}},
+		{"é", token.Position{Offset: 30, Line: 2, Column: 7}, token.Position{Offset: 32, Line: 2, Column: 8}},
+		{")", token.Position{Offset: 32, Line: 2, Column: 8}, token.Position{Offset: 33, Line: 2, Column: 9}},
+	}
+
+	l := NewLexer(input)
+	tokens := l.Tokenize()
+
+	for i, tt := range tests {
+		tok := tokens[i]
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Pos != tt.expectedPos {
+			t.Errorf("tests[%d] - pos wrong. expected=%+v, got=%+v", i, tt.expectedPos, tok.Pos)
+		}
+
+		if tok.End != tt.expectedEnd {
+			t.Errorf("tests[%d] - end wrong. expected=%+v, got=%+v", i, tt.expectedEnd, tok.End)
+		}
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedLiteral string
+	}{
+		{`"plain"`, "plain"},
+		{`"say \"meow\""`, `say "meow"`},
+		{`"back\\slash"`, `back\slash`},
+		{`"line\nbreak"`, "line\nbreak"},
+		{`"tab\there"`, "tab\there"},
+		{`"carriage\rreturn"`, "carriage\rreturn"},
+		{`"nul\0"`, "nul\x00"},
+		{`"cat \u{1F431}!"`, "cat 🐱!"},
+		{`"\u{e9}t\u{E9}"`, "été"},
+		{"`raw \\n \"string\"`", `raw \n "string"`},
+		{"`multi\nline`", "multi\nline"},
+	}
+
+	for _, tt := range tests {
+		l := NewLexer(tt.input)
+		tokens := l.Tokenize()
+
+		if len(l.Errors()) != 0 {
+			t.Errorf("input %s: unexpected errors %q", tt.input, l.Errors())
+		}
+
+		compareTokens(t, tokens, []struct {
+			expectedType    token.TokenType
+			expectedLiteral string
+		}{
+			{token.STRING, tt.expectedLiteral}, {token.EOF, ""},
+		})
+	}
+}
+
+func TestStringErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedLiteral string
+		expectedErrors  []string
+	}{
+		{`purr "never ends`, "never ends", []string{"1:6: unterminated string literal"}},
+		{"purr `never ends", "never ends", []string{"1:6: unterminated raw string literal"}},
+		{`purr "bad \q escape"`, `bad \q escape`, []string{"1:11: invalid escape sequence '\\q'"}},
+		{`purr "\u1F431"`, `\u1F431`, []string{"1:7: invalid unicode escape sequence, expected '\\u{...}'"}},
+		{`purr "\u{1F431"`, `\u{1F431`, []string{"1:7: invalid unicode escape sequence, expected '\\u{...}'"}},
+		{`purr "\u{zz}"`, `\u{zz}`, []string{"1:7: invalid unicode code point '\\u{zz}'"}},
+		{`purr "\u{D800}"`, `\u{D800}`, []string{"1:7: invalid unicode code point '\\u{D800}'"}},
+		{
+			"purr \"a\\x\"\npurr \"b",
+			"a\\x",
+			[]string{"1:8: invalid escape sequence '\\x'", "2:6: unterminated string literal"},
+		},
+		{
+			"purr \"first\npurr \"second\"",
+			"first",
+			[]string{"1:6: unterminated string literal"},
+		},
+	}
+
+	for _, tt := range tests {
+		l := NewLexer(tt.input)
+		tokens := l.Tokenize()
+
+		if tokens[1].Type != token.STRING || tokens[1].Literal != tt.expectedLiteral {
+			t.Errorf("input %q: expected STRING %q, got %s %q", tt.input, tt.expectedLiteral, tokens[1].Type, tokens[1].Literal)
+		}
+
+		errors := l.Errors()
+		if len(errors) != len(tt.expectedErrors) {
+			t.Errorf("input %q: expected %d errors, got %d: %q", tt.input, len(tt.expectedErrors), len(errors), errors)
+			continue
+		}
+		for i, expected := range tt.expectedErrors {
+			if errors[i] != expected {
+				t.Errorf("input %q: errors[%d] expected %q, got %q", tt.input, i, expected, errors[i])
+			}
+		}
+	}
+}
+
+func TestInterpolatedStrings(t *testing.T) {
+	input := `purr "Result of {op}: {add(a, b)}!" + "{"x"}" + "\{literal\}" + "{ {} }"`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.PURR, "purr"},
+		{token.TEMPLATE_HEAD, "Result of "}, {token.IDENT, "op"},
+		{token.TEMPLATE_MIDDLE, ": "}, {token.IDENT, "add"}, {token.LPAREN, "("}, {token.IDENT, "a"}, {token.COMMA, ","}, {token.IDENT, "b"}, {token.RPAREN, ")"},
+		{token.TEMPLATE_TAIL, "!"},
+		{token.PLUS, "+"},
+		{token.TEMPLATE_HEAD, ""}, {token.STRING, "x"}, {token.TEMPLATE_TAIL, ""},
+		{token.PLUS, "+"},
+		{token.STRING, "{literal}"},
+		{token.PLUS, "+"},
+		{token.TEMPLATE_HEAD, ""}, {token.LBRACE, "{"}, {token.RBRACE, "}"}, {token.TEMPLATE_TAIL, ""},
+		{token.EOF, ""},
+	}
+
+	l := NewLexer(input)
+	tokens := l.Tokenize()
+
+	if len(l.Errors()) != 0 {
+		t.Errorf("unexpected errors %q", l.Errors())
+	}
+
+	compareTokens(t, tokens, tests)
+}
+
+func TestInterpolatedStringPositions(t *testing.T) {
+	input := `purr "a {x} b"`
+
+	tests := []struct {
+		expectedType token.TokenType
+		expectedPos  string
+		expectedEnd  string
+	}{
+		{token.PURR, "1:1", "1:5"},
+		{token.TEMPLATE_HEAD, "1:6", "1:10"},
+		{token.IDENT, "1:10", "1:11"},
+		{token.TEMPLATE_TAIL, "1:11", "1:15"},
+		{token.EOF, "1:15", "1:15"},
+	}
+
+	l := NewLexer(input)
+	tokens := l.Tokenize()
+
+	for i, tt := range tests {
+		tok := tokens[i]
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Pos.String() != tt.expectedPos {
+			t.Errorf("tests[%d] - pos wrong. expected=%s, got=%s", i, tt.expectedPos, tok.Pos)
+		}
+		if tok.End.String() != tt.expectedEnd {
+			t.Errorf("tests[%d] - end wrong. expected=%s, got=%s", i, tt.expectedEnd, tok.End)
+		}
+	}
+}
+
+func TestInterpolationErrors(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedErrors []string
+	}{
+		{`purr "a {x`, []string{"1:6: unterminated string interpolation"}},
+		{`purr "a {x} b`, []string{"1:6: unterminated string literal"}},
+		{`purr "a{}b"`, []string{"1:8: empty interpolation"}},
+		{`purr "{ } and {x}"`, []string{"1:7: empty interpolation"}},
+	}
+
+	for _, tt := range tests {
+		l := NewLexer(tt.input)
+		l.Tokenize()
+
+		errors := l.Errors()
+		if len(errors) != len(tt.expectedErrors) {
+			t.Errorf("input %q: expected %d errors, got %d: %q", tt.input, len(tt.expectedErrors), len(errors), errors)
+			continue
+		}
+		for i, expected := range tt.expectedErrors {
+			if errors[i] != expected {
+				t.Errorf("input %q: errors[%d] expected %q, got %q", tt.input, i, expected, errors[i])
+			}
+		}
+	}
+}
+
+func TestNumberLiterals(t *testing.T) {
+	input := `42 1.5 0.25 1e3 2.5E-3 6e+2 7. 3.x`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "42"}, {token.FLOAT, "1.5"}, {token.FLOAT, "0.25"}, {token.FLOAT, "1e3"},
+		{token.FLOAT, "2.5E-3"}, {token.FLOAT, "6e+2"},
+		{token.INT, "7"}, {token.ILLEGAL, "."},
+		{token.INT, "3"}, {token.ILLEGAL, "."}, {token.IDENT, "x"},
+		{token.EOF, ""},
+	}
+
+	l := NewLexer(input)
+	tokens := l.Tokenize()
+
+	compareTokens(t, tokens, tests)
+
+	if len(l.Errors()) != 0 {
+		t.Errorf("expected no errors, got %q", l.Errors())
+	}
+}
+
+func TestNumberLiteralErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedLiteral string
+		expectedError   string
+	}{
+		{`purr 1e`, "1e", "1:6: exponent has no digits in number literal 1e"},
+		{`purr 2.5e+`, "2.5e+", "1:6: exponent has no digits in number literal 2.5e+"},
+	}
+
+	for _, tt := range tests {
+		l := NewLexer(tt.input)
+		tokens := l.Tokenize()
+
+		if tokens[1].Type != token.FLOAT || tokens[1].Literal != tt.expectedLiteral {
+			t.Errorf("input %q: expected FLOAT %q, got %s %q", tt.input, tt.expectedLiteral, tokens[1].Type, tokens[1].Literal)
+		}
+
+		errors := l.Errors()
+		if len(errors) != 1 || errors[0] != tt.expectedError {
+			t.Errorf("input %q: expected error %q, got %q", tt.input, tt.expectedError, errors)
+		}
+	}
+}
+
+func TestBrackets(t *testing.T) {
+	input := `xs[0] = [1, 2]`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "xs"}, {token.LBRACKET, "["}, {token.INT, "0"}, {token.RBRACKET, "]"},
+		{token.ASSIGN, "="}, {token.LBRACKET, "["}, {token.INT, "1"}, {token.COMMA, ","},
+		{token.INT, "2"}, {token.RBRACKET, "]"},
+		{token.EOF, ""},
+	}
+
+	l := NewLexer(input)
+	tokens := l.Tokenize()
+
+	compareTokens(t, tokens, tests)
+}
+
+func TestMapTokens(t *testing.T) {
+	input := `lick m = {"age": 3} shoo m["age"]`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LICK, "lick"}, {token.IDENT, "m"}, {token.ASSIGN, "="},
+		{token.LBRACE, "{"}, {token.STRING, "age"}, {token.COLON, ":"}, {token.INT, "3"}, {token.RBRACE, "}"},
+		{token.SHOO, "shoo"}, {token.IDENT, "m"}, {token.LBRACKET, "["}, {token.STRING, "age"}, {token.RBRACKET, "]"},
+		{token.EOF, ""},
+	}
+
+	l := NewLexer(input)
+	tokens := l.Tokenize()
+
+	compareTokens(t, tokens, tests)
+}
@@ -0,0 +1,42 @@
+package meow
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/AlyxPink/meowlang/interpreter"
+	"github.com/AlyxPink/meowlang/vm"
+)
+
+// Backend selects how programs run. Both backends behave the same.
+type Backend string
+
+const (
+	// Interpreter walks the syntax tree of the program. It is the default.
+	Interpreter Backend = "interpreter"
+	// VM compiles the program to bytecode, run by a stack machine.
+	VM Backend = "vm"
+)
+
+// Validate returns an error if b is not a known backend. The empty Backend
+// is valid, it is the Interpreter.
+func (b Backend) Validate() error {
+	switch b {
+	case Interpreter, VM, "":
+		return nil
+	default:
+		return fmt.Errorf("unknown backend %q, want %q or %q", b, Interpreter, VM)
+	}
+}
+
+// NewEngine creates an engine running programs with backend, printing to
+// stdout and writing diagnostics to stderr. The empty Backend is the Interpreter.
+func NewEngine(backend Backend, stdout, stderr io.Writer) (interpreter.Engine, error) {
+	if err := backend.Validate(); err != nil {
+		return nil, err
+	}
+	if backend == VM {
+		return vm.NewWithWriters(stdout, stderr), nil
+	}
+	return interpreter.NewInterpreterWithWriters(stdout, stderr), nil
+}
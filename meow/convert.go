@@ -0,0 +1,390 @@
+package meow
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+
+	"github.com/AlyxPink/meowlang/object"
+)
+
+var (
+	errorType  = reflect.TypeOf((*error)(nil)).Elem()
+	objectType = reflect.TypeOf((*object.Object)(nil)).Elem()
+	bigIntType = reflect.TypeOf((*big.Int)(nil))
+)
+
+// ToGo converts a MeowLang value to Go:
+//   - INTEGER to int64, or *big.Int if it does not fit
+//   - FLOAT to float64, STRING to string and BOOLEAN to bool
+//   - NULL to nil
+//   - ARRAY to []interface{} and MAP to map[interface{}]interface{}
+//
+// Functions are returned as is, as an object.Object, so they can be passed
+// back to MeowLang. An array or a map inside itself converts to nil there.
+func ToGo(obj object.Object) interface{} {
+	return toGo(obj, make(map[object.Object]bool))
+}
+
+// toGo converts a MeowLang value to Go, seen holds the collections being converted around it.
+func toGo(obj object.Object, seen map[object.Object]bool) interface{} {
+	switch obj := obj.(type) {
+	case nil, *object.Null:
+		return nil
+	case *object.Integer:
+		if obj.IsBig() {
+			return obj.BigValue()
+		}
+		return obj.Value
+	case *object.Float:
+		return obj.Value
+	case *object.String:
+		return obj.Value
+	case *object.Boolean:
+		return obj.Value
+	case *object.Array:
+		if seen[obj] {
+			return nil
+		}
+		seen[obj] = true
+		defer delete(seen, obj)
+
+		elements := make([]interface{}, len(obj.Elements))
+		for i, element := range obj.Elements {
+			elements[i] = toGo(element, seen)
+		}
+		return elements
+	case *object.Map:
+		if seen[obj] {
+			return nil
+		}
+		seen[obj] = true
+		defer delete(seen, obj)
+
+		m := make(map[interface{}]interface{}, obj.Len())
+		for _, pair := range obj.Pairs() {
+			key := toGo(pair.Key, seen)
+			if n, ok := key.(*big.Int); ok {
+				key = n.String() // a *big.Int would be compared by pointer
+			}
+			m[key] = toGo(pair.Value, seen)
+		}
+		return m
+	default:
+		return obj
+	}
+}
+
+// ToObject converts a Go value to MeowLang, the other way around of ToGo. It
+// accepts any integer, float, string and bool types, slices and arrays, maps
+// with keys that convert to integers, strings or booleans, and functions.
+//
+// A Go function becomes a built-in function. Its arguments are converted
+// with ToGo, then to the type of its parameters. It may return nothing, a
+// value, an error, or a value and an error, which is turned into a runtime error.
+//
+// A map, slice or pointer that contains itself cannot be converted.
+func ToObject(value interface{}) (object.Object, error) {
+	return toObject("func", value, make(map[goRef]bool))
+}
+
+// goRef identifies a Go map, slice or pointer, to find the values that contain themselves.
+type goRef struct {
+	typ reflect.Type
+	ptr uintptr
+	len int
+}
+
+// enter records that rv, a map, slice or pointer, is being converted. It fails
+// if rv is already being converted around it, since it contains itself. leave
+// must be called once rv is converted.
+func enter(rv reflect.Value, seen map[goRef]bool) (leave func(), err error) {
+	ref := goRef{typ: rv.Type(), ptr: rv.Pointer()}
+	if rv.Kind() == reflect.Slice {
+		ref.len = rv.Len()
+	}
+	if seen[ref] {
+		return nil, fmt.Errorf("cyclic Go value of type %s", rv.Type())
+	}
+	seen[ref] = true
+	return func() { delete(seen, ref) }, nil
+}
+
+// toObject converts a Go value to MeowLang, seen holds the maps, slices and
+// pointers being converted around it. Functions are named after name in error messages.
+func toObject(name string, value interface{}, seen map[goRef]bool) (object.Object, error) {
+	switch value := value.(type) {
+	case nil:
+		return &object.Null{}, nil
+	case object.Object:
+		return value, nil
+	case *big.Int:
+		return object.NewBigInteger(new(big.Int).Set(value)), nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &object.Integer{Value: rv.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return object.NewBigInteger(new(big.Int).SetUint64(rv.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return &object.Float{Value: rv.Float()}, nil
+	case reflect.String:
+		return &object.String{Value: rv.String()}, nil
+	case reflect.Bool:
+		return &object.Boolean{Value: rv.Bool()}, nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice {
+			leave, err := enter(rv, seen)
+			if err != nil {
+				return nil, err
+			}
+			defer leave()
+		}
+
+		elements := make([]object.Object, rv.Len())
+		for i := range elements {
+			element, err := toObject(name, rv.Index(i).Interface(), seen)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = element
+		}
+		return &object.Array{Elements: elements}, nil
+	case reflect.Map:
+		return mapToObject(name, rv, seen)
+	case reflect.Func:
+		return funcToObject(name, rv)
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return &object.Null{}, nil
+		}
+		if rv.Kind() == reflect.Pointer {
+			leave, err := enter(rv, seen)
+			if err != nil {
+				return nil, err
+			}
+			defer leave()
+		}
+		return toObject(name, rv.Elem().Interface(), seen)
+	}
+
+	return nil, fmt.Errorf("unsupported Go type %s", rv.Type())
+}
+
+// mapToObject converts a Go map to a MeowLang map. Go maps are unordered, so
+// the entries are inserted sorted by key, to always print the same way.
+func mapToObject(name string, rv reflect.Value, seen map[goRef]bool) (object.Object, error) {
+	leave, err := enter(rv, seen)
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
+	keys := rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+
+	m := object.NewMap()
+	for _, key := range keys {
+		keyObj, err := toObject(name, key.Interface(), seen)
+		if err != nil {
+			return nil, err
+		}
+		hashKey, ok := keyObj.(object.Hashable)
+		if !ok {
+			return nil, fmt.Errorf("unusable as map key: %s", keyObj.Type())
+		}
+
+		value, err := toObject(name, rv.MapIndex(key).Interface(), seen)
+		if err != nil {
+			return nil, err
+		}
+		m.Set(hashKey, value)
+	}
+	return m, nil
+}
+
+// funcToObject wraps a Go function in a built-in function. A panic in the
+// function is turned into a runtime error.
+func funcToObject(name string, fn reflect.Value) (object.Object, error) {
+	if fn.IsNil() {
+		return &object.Null{}, nil
+	}
+	typ := fn.Type()
+
+	returnsError := typ.NumOut() > 0 && typ.Out(typ.NumOut()-1) == errorType
+	switch {
+	case typ.NumOut() > 2,
+		typ.NumOut() == 2 && !returnsError:
+		return nil, fmt.Errorf("unsupported Go function %s, it must return at most a value and an error", typ)
+	}
+
+	builtin := func(rt object.Runtime, args ...object.Object) (result object.Object) {
+		minArgs := typ.NumIn()
+		if typ.IsVariadic() {
+			minArgs--
+		}
+		if len(args) < minArgs || (!typ.IsVariadic() && len(args) > minArgs) {
+			want := fmt.Sprint(minArgs)
+			if typ.IsVariadic() {
+				want = "at least " + want
+			}
+			return &object.Error{Message: fmt.Sprintf("wrong number of arguments to %s: want=%s, got=%d", name, want, len(args))}
+		}
+
+		in := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			var paramType reflect.Type
+			if typ.IsVariadic() && i >= minArgs {
+				paramType = typ.In(minArgs).Elem()
+			} else {
+				paramType = typ.In(i)
+			}
+
+			value, err := fromObject(arg, paramType)
+			if err != nil {
+				return &object.Error{Message: fmt.Sprintf("argument %d to %s: %s", i+1, name, err)}
+			}
+			in[i] = value
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				result = &object.Error{Message: fmt.Sprintf("%s panicked: %v", name, r)}
+			}
+		}()
+		out := fn.Call(in)
+
+		if returnsError {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				return &object.Error{Message: err.Error()}
+			}
+			out = out[:len(out)-1]
+		}
+		if len(out) == 0 {
+			return &object.Null{}
+		}
+
+		obj, err := toObject(name, out[0].Interface(), make(map[goRef]bool))
+		if err != nil {
+			return &object.Error{Message: fmt.Sprintf("result of %s: %s", name, err)}
+		}
+		return obj
+	}
+
+	return &object.Builtin{Name: name, Fn: builtin}, nil
+}
+
+// fromObject converts a MeowLang value to a Go value of the given type.
+// Numbers are only converted between numeric types without losing their
+// value, e.g. a FLOAT is not accepted for an int.
+func fromObject(obj object.Object, typ reflect.Type) (reflect.Value, error) {
+	if typ == objectType {
+		return reflect.ValueOf(&obj).Elem(), nil
+	}
+	if integer, ok := obj.(*object.Integer); ok && typ == bigIntType {
+		return reflect.ValueOf(integer.BigValue()), nil
+	}
+
+	value := ToGo(obj)
+	if value == nil {
+		switch typ.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Slice, reflect.Map, reflect.Func:
+			return reflect.Zero(typ), nil
+		}
+		return reflect.Value{}, fmt.Errorf("cannot use NULL as %s", typ)
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Type().AssignableTo(typ) {
+		return rv, nil
+	}
+
+	mismatch := fmt.Errorf("cannot use %s as %s", obj.Type(), typ)
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+		converted := reflect.New(typ).Elem()
+		n := integer.BigValue()
+		switch {
+		case n.IsInt64() && isSignedKind(typ.Kind()) && !converted.OverflowInt(n.Int64()):
+			converted.SetInt(n.Int64())
+		case n.IsUint64() && !isSignedKind(typ.Kind()) && !converted.OverflowUint(n.Uint64()):
+			converted.SetUint(n.Uint64())
+		default:
+			return reflect.Value{}, fmt.Errorf("%s overflows %s", integer.Inspect(), typ)
+		}
+		return converted, nil
+	case reflect.Float32, reflect.Float64:
+		switch obj := obj.(type) {
+		case *object.Integer:
+			f, _ := new(big.Float).SetInt(obj.BigValue()).Float64()
+			return reflect.ValueOf(f).Convert(typ), nil
+		case *object.Float:
+			return reflect.ValueOf(obj.Value).Convert(typ), nil
+		}
+		return reflect.Value{}, mismatch
+	case reflect.String:
+		if rv.Kind() != reflect.String {
+			return reflect.Value{}, mismatch
+		}
+		return rv.Convert(typ), nil
+	case reflect.Bool:
+		if rv.Kind() != reflect.Bool {
+			return reflect.Value{}, mismatch
+		}
+		return rv.Convert(typ), nil
+	case reflect.Slice:
+		array, ok := obj.(*object.Array)
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+		slice := reflect.MakeSlice(typ, len(array.Elements), len(array.Elements))
+		for i, element := range array.Elements {
+			value, err := fromObject(element, typ.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			slice.Index(i).Set(value)
+		}
+		return slice, nil
+	case reflect.Map:
+		m, ok := obj.(*object.Map)
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+		converted := reflect.MakeMapWithSize(typ, m.Len())
+		for _, pair := range m.Pairs() {
+			key, err := fromObject(pair.Key, typ.Key())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			value, err := fromObject(pair.Value, typ.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			converted.SetMapIndex(key, value)
+		}
+		return converted, nil
+	}
+
+	return reflect.Value{}, mismatch
+}
+
+// isSignedKind reports whether a kind is a signed integer.
+func isSignedKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
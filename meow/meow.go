@@ -0,0 +1,206 @@
+// Package meow embeds MeowLang in Go programs.
+//
+// A program is compiled once with Compile, then run with Run:
+//
+//	program, err := meow.Compile(`purr "Hello, {name}!"`)
+//	if err != nil {
+//		return err
+//	}
+//	result, err := meow.Run(ctx, program, &meow.Options{
+//		Globals: map[string]interface{}{"name": "Mochi"},
+//	})
+//
+// Go values and functions bound with Options.Globals are converted to MeowLang
+// values, see ToObject. The functions defined by the program can then be
+// called back from Go with Result.Call.
+//
+// Untrusted programs can be bounded with Options.Limits and the context
+// passed to Run, see Limits.
+package meow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/interpreter"
+	"github.com/AlyxPink/meowlang/lexer"
+	"github.com/AlyxPink/meowlang/object"
+	"github.com/AlyxPink/meowlang/parser"
+)
+
+// Program is a parsed MeowLang program, ready to be run any number of times.
+type Program struct {
+	ast *ast.Program
+}
+
+// CompileError lists the syntax errors of a program, each located as
+// "line:column: message".
+type CompileError struct {
+	Errors []string
+}
+
+func (e *CompileError) Error() string {
+	return strings.Join(e.Errors, "\n")
+}
+
+// Compile parses a program. It returns a *CompileError if it has syntax errors.
+func Compile(src string) (*Program, error) {
+	l := lexer.NewLexer(src)
+	p := parser.NewParser(l.Tokenize())
+	program := p.ParseProgram()
+
+	if errors := append(l.Errors(), p.Errors()...); len(errors) > 0 {
+		return nil, &CompileError{Errors: errors}
+	}
+	return &Program{ast: program}, nil
+}
+
+// Options configures how a program runs.
+type Options struct {
+	// Stdout receives what the program prints, as it prints it, os.Stdout if nil.
+	Stdout io.Writer
+	// Stderr receives diagnostics, e.g. the runtime error that stopped the
+	// program, os.Stderr if nil.
+	Stderr io.Writer
+	// Name names the program in the diagnostics, e.g. its file name.
+	Name string
+	// Stdin is read by the 'input' built-in function, os.Stdin if nil.
+	Stdin io.Reader
+	// Globals are Go values and functions defined as global variables of the
+	// program, converted with ToObject.
+	Globals map[string]interface{}
+	// Limits bounds the resources used by Run, and by each Result.Call.
+	Limits Limits
+	// Backend runs the program, the Interpreter if empty.
+	Backend Backend
+}
+
+// Limits bounds the resources a program may use. A zero field means no limit,
+// except for MaxCallDepth which defaults to interpreter.DefaultMaxCallDepth.
+// Exceeding one stops the program with an *object.Error wrapping a *LimitError.
+type Limits = interpreter.Limits
+
+// LimitError tells which of the Limits a program exceeded, see errors.As.
+type LimitError = interpreter.LimitError
+
+// Result is a program that ran, giving access to its global variables.
+type Result struct {
+	// Value is the value of the last statement of the program, converted with ToGo.
+	Value interface{}
+
+	engine interpreter.Engine
+}
+
+// Run runs a program. Runtime errors are returned as a *object.Error, which
+// is located at the token that caused it, and written to Options.Stderr. The
+// program stops as soon as ctx is done, with an error wrapping the error of ctx.
+func Run(ctx context.Context, program *Program, opts *Options) (*Result, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	stdout, stderr := opts.Stdout, opts.Stderr
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
+	engine, err := NewEngine(opts.Backend, stdout, stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{engine: engine}
+	result.engine.SetLimits(opts.Limits)
+	if opts.Stdin != nil {
+		result.engine.SetInput(opts.Stdin)
+	}
+
+	for name, value := range opts.Globals {
+		obj, err := toObject(name, value, make(map[goRef]bool))
+		if err != nil {
+			return nil, fmt.Errorf("cannot bind %s: %w", name, err)
+		}
+		result.engine.Env().Set(name, obj)
+	}
+
+	value, err := result.run(ctx, func() object.Object {
+		return result.engine.InterpretContext(ctx, program.ast)
+	})
+	if err != nil {
+		var runtimeErr *object.Error
+		if errors.As(err, &runtimeErr) {
+			fmt.Fprintln(stderr, Diagnostic(opts.Name, err))
+		}
+		return nil, err
+	}
+
+	result.Value = value
+	return result, nil
+}
+
+// Diagnostic formats an error of the program named name, e.g.
+// "cat.meow:3:7: division by zero", or "cat.meow: ..." if it has no position.
+// The error is left as is if name is empty.
+func Diagnostic(name string, err error) string {
+	if name == "" {
+		return err.Error()
+	}
+	var runtimeErr *object.Error
+	if errors.As(err, &runtimeErr) && runtimeErr.Token.Pos.IsValid() {
+		return name + ":" + err.Error()
+	}
+	return name + ": " + err.Error()
+}
+
+// Get returns the value of a global variable, converted with ToGo.
+func (r *Result) Get(name string) (interface{}, bool) {
+	obj, ok := r.engine.Env().Get(name)
+	if !ok {
+		return nil, false
+	}
+	return ToGo(obj), true
+}
+
+// Call calls a function defined by the program, or bound with Options.Globals.
+// The arguments are converted with ToObject, and the result with ToGo.
+func (r *Result) Call(ctx context.Context, name string, args ...interface{}) (interface{}, error) {
+	fn, ok := r.engine.Env().Get(name)
+	if !ok {
+		return nil, fmt.Errorf("identifier not found: %s", name)
+	}
+
+	objects := make([]object.Object, len(args))
+	for i, arg := range args {
+		obj, err := ToObject(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d to %s: %w", i+1, name, err)
+		}
+		objects[i] = obj
+	}
+
+	return r.run(ctx, func() object.Object {
+		return r.engine.CallContext(ctx, fn, objects...)
+	})
+}
+
+// run evaluates MeowLang code, converting its result to Go.
+func (r *Result) run(ctx context.Context, eval func() object.Object) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	obj := eval()
+
+	if err, ok := obj.(*object.Error); ok {
+		return nil, err
+	}
+	return ToGo(obj), nil
+}
@@ -0,0 +1,483 @@
+package meow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AlyxPink/meowlang/interpreter"
+	"github.com/AlyxPink/meowlang/object"
+	"github.com/AlyxPink/meowlang/token"
+)
+
+// run compiles and runs a program, failing the test on any error.
+func run(t *testing.T, src string, opts *Options) (*Result, string) {
+	t.Helper()
+
+	program, err := Compile(src)
+	if err != nil {
+		t.Fatalf("Compile(%q) failed: %v", src, err)
+	}
+
+	var out bytes.Buffer
+	if opts == nil {
+		opts = &Options{}
+	}
+	opts.Stdout = &out
+
+	result, err := Run(context.Background(), program, opts)
+	if err != nil {
+		t.Fatalf("Run(%q) failed: %v", src, err)
+	}
+	return result, out.String()
+}
+
+func TestCompileErrors(t *testing.T) {
+	_, err := Compile("lick = 1\npurr \"open")
+
+	var compileErr *CompileError
+	if !errors.As(err, &compileErr) {
+		t.Fatalf("expected *CompileError, got %T (%v)", err, err)
+	}
+
+	expected := []string{"2:6: unterminated string literal", "1:6: expected next token to be IDENT, got '=' instead"}
+	if !reflect.DeepEqual(compileErr.Errors, expected) {
+		t.Errorf("expected errors %q, got %q", expected, compileErr.Errors)
+	}
+	if err.Error() != strings.Join(expected, "\n") {
+		t.Errorf("expected error message %q, got %q", strings.Join(expected, "\n"), err.Error())
+	}
+}
+
+func TestCompileErrorsAreReportedOnce(t *testing.T) {
+	_, err := Compile("purr 1e")
+
+	var compileErr *CompileError
+	if !errors.As(err, &compileErr) {
+		t.Fatalf("expected *CompileError, got %T (%v)", err, err)
+	}
+
+	expected := []string{"1:6: exponent has no digits in number literal 1e"}
+	if !reflect.DeepEqual(compileErr.Errors, expected) {
+		t.Errorf("expected errors %q, got %q", expected, compileErr.Errors)
+	}
+}
+
+func TestRun(t *testing.T) {
+	result, output := run(t, `purr "meow" lick x = 40 x + 2`, nil)
+
+	if output != "meow\n" {
+		t.Errorf("expected output %q, got %q", "meow\n", output)
+	}
+	if result.Value != int64(42) {
+		t.Errorf("expected value 42, got %#v", result.Value)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	program, err := Compile(`purr "before" purr 1 + true purr "after"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out, stderr bytes.Buffer
+	_, err = Run(context.Background(), program, &Options{Stdout: &out, Stderr: &stderr, Name: "cat.meow"})
+
+	var runtimeErr *object.Error
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("expected *object.Error, got %T (%v)", err, err)
+	}
+	if err.Error() != "1:22: type mismatch: INTEGER + BOOLEAN" {
+		t.Errorf("expected error %q, got %q", "1:22: type mismatch: INTEGER + BOOLEAN", err.Error())
+	}
+	if out.String() != "before\n" {
+		t.Errorf("expected output %q, got %q", "before\n", out.String())
+	}
+	if stderr.String() != "cat.meow:1:22: type mismatch: INTEGER + BOOLEAN\n" {
+		t.Errorf("expected stderr %q, got %q", "cat.meow:1:22: type mismatch: INTEGER + BOOLEAN\n", stderr.String())
+	}
+}
+
+func TestDiagnostic(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"cat.meow", &object.Error{Message: "division by zero", Token: token.Token{Pos: token.Position{Line: 3, Column: 7}}}, "cat.meow:3:7: division by zero"},
+		{"cat.meow", &object.Error{Message: "context canceled"}, "cat.meow: context canceled"},
+		{"cat.meow", errors.New("cannot bind x"), "cat.meow: cannot bind x"},
+		{"", errors.New("cannot bind x"), "cannot bind x"},
+	}
+
+	for _, tt := range tests {
+		if got := Diagnostic(tt.name, tt.err); got != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, got)
+		}
+	}
+}
+
+func TestRunCanceledContext(t *testing.T) {
+	program, err := Compile(`purr 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Run(ctx, program, &Options{Stdout: new(bytes.Buffer), Stderr: io.Discard}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRunStreamsOutput(t *testing.T) {
+	program, err := Compile(`purr "first" check() purr "second"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out, stderr bytes.Buffer
+	var printed string
+	globals := map[string]interface{}{
+		"check": func() { printed = out.String() },
+	}
+
+	if _, err := Run(context.Background(), program, &Options{Stdout: &out, Stderr: &stderr, Globals: globals}); err != nil {
+		t.Fatal(err)
+	}
+	if printed != "first\n" {
+		t.Errorf("expected %q to be printed before check(), got %q", "first\n", printed)
+	}
+	if out.String() != "first\nsecond\n" {
+		t.Errorf("expected output %q, got %q", "first\nsecond\n", out.String())
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("expected nothing on stderr, got %q", stderr.String())
+	}
+}
+
+func TestRunLimits(t *testing.T) {
+	program, err := Compile("meow loop(n) {\n  claw loop(n + 1)\n}\nloop(0)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Run(context.Background(), program, &Options{Stdout: new(bytes.Buffer), Stderr: io.Discard, Limits: Limits{MaxCallDepth: 100}})
+
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected *LimitError, got %T (%v)", err, err)
+	}
+	if limitErr.Limit != interpreter.LimitCallDepth || limitErr.Max != 100 {
+		t.Errorf("expected the call depth limit of 100, got %#v", limitErr)
+	}
+	if err.Error() != "2:12: call depth limit of 100 exceeded" {
+		t.Errorf("expected error %q, got %q", "2:12: call depth limit of 100 exceeded", err.Error())
+	}
+}
+
+func TestCallLimits(t *testing.T) {
+	result, _ := run(t, `meow spin() { scratch (true) {} }`, &Options{Limits: Limits{MaxSteps: 1000}})
+
+	_, err := result.Call(context.Background(), "spin")
+
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != interpreter.LimitSteps {
+		t.Errorf("expected a step limit error, got %v", err)
+	}
+}
+
+func TestRunStdin(t *testing.T) {
+	_, output := run(t, `purr "Hello, " + input() + "!"`, &Options{Stdin: strings.NewReader("Mochi\n")})
+
+	if output != "Hello, Mochi!\n" {
+		t.Errorf("expected output %q, got %q", "Hello, Mochi!\n", output)
+	}
+}
+
+func TestGlobals(t *testing.T) {
+	globals := map[string]interface{}{
+		"name":   "Mochi",
+		"age":    3,
+		"weight": float32(4.5),
+		"indoor": true,
+		"toys":   []string{"mouse", "ball"},
+		"meals":  map[string]int{"dinner": 2, "breakfast": 1},
+		"huge":   uint64(18446744073709551615),
+		"none":   nil,
+	}
+	src := `
+	purr name + " " + age + " " + weight + " " + indoor
+	purr toys[1]
+	purr meals
+	purr huge
+	purr none`
+
+	_, output := run(t, src, &Options{Globals: globals})
+
+	expected := "Mochi 3 4.5 true\nball\n{\"breakfast\": 1, \"dinner\": 2}\n18446744073709551615\nnull\n"
+	if output != expected {
+		t.Errorf("expected output %q, got %q", expected, output)
+	}
+}
+
+func TestGlobalFunctions(t *testing.T) {
+	globals := map[string]interface{}{
+		"greet": func(name string) string { return "Hello, " + name },
+		"half": func(n int) (int, error) {
+			if n%2 != 0 {
+				return 0, fmt.Errorf("%d is odd", n)
+			}
+			return n / 2, nil
+		},
+		"sum": func(numbers ...float64) float64 {
+			total := 0.0
+			for _, n := range numbers {
+				total += n
+			}
+			return total
+		},
+		"join":  func(words []string, sep string) string { return strings.Join(words, sep) },
+		"boom":  func() { panic("oh no") },
+		"apply": func(fn object.Object) object.Object { return fn },
+	}
+
+	tests := []struct {
+		src            string
+		expectedOutput string
+		expectedError  string
+	}{
+		{`purr greet("Mochi")`, "Hello, Mochi\n", ""},
+		{`purr half(10)`, "5\n", ""},
+		{`purr sum() + sum(1, 2.5)`, "3.5\n", ""},
+		{`purr join(["a", "b"], "-")`, "a-b\n", ""},
+		{`purr apply(len)("abc")`, "3\n", ""},
+		{`purr half(3)`, "", "1:10: 3 is odd"},
+		{`purr greet()`, "", "1:11: wrong number of arguments to greet: want=1, got=0"},
+		{`purr greet(1)`, "", "1:11: argument 1 to greet: cannot use INTEGER as string"},
+		{`purr half(1.5)`, "", "1:10: argument 1 to half: cannot use FLOAT as int"},
+		{`purr half(99999999999999999999)`, "", "1:10: argument 1 to half: 99999999999999999999 overflows int"},
+		{`purr join([1], "-")`, "", "1:10: argument 1 to join: cannot use INTEGER as string"},
+		{`boom()`, "", "1:5: boom panicked: oh no"},
+	}
+
+	for _, tt := range tests {
+		program, err := Compile(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var out bytes.Buffer
+		_, err = Run(context.Background(), program, &Options{Stdout: &out, Stderr: io.Discard, Globals: globals})
+
+		if tt.expectedError != "" {
+			if err == nil || err.Error() != tt.expectedError {
+				t.Errorf("%q: expected error %q, got %v", tt.src, tt.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.src, err)
+		}
+		if out.String() != tt.expectedOutput {
+			t.Errorf("%q: expected output %q, got %q", tt.src, tt.expectedOutput, out.String())
+		}
+	}
+}
+
+func TestUnsupportedGlobals(t *testing.T) {
+	program, err := Compile(`purr 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value         interface{}
+		expectedError string
+	}{
+		{make(chan int), "cannot bind value: unsupported Go type chan int"},
+		{func() (int, int) { return 1, 2 }, "cannot bind value: unsupported Go function func() (int, int), it must return at most a value and an error"},
+		{map[float64]int{1.5: 1}, "cannot bind value: unusable as map key: FLOAT"},
+	}
+
+	for _, tt := range tests {
+		_, err := Run(context.Background(), program, &Options{Globals: map[string]interface{}{"value": tt.value}})
+		if err == nil || err.Error() != tt.expectedError {
+			t.Errorf("expected error %q, got %v", tt.expectedError, err)
+		}
+	}
+}
+
+func TestResultGetAndCall(t *testing.T) {
+	src := `
+	lick count = 0
+	meow add(a, b) {
+		count = count + 1
+		claw a + b
+	}
+	lick pair = meow(a, b) { claw [a, b] }
+	meow fail() { claw 1 / 0 }`
+
+	for _, backend := range []Backend{Interpreter, VM} {
+		t.Run(string(backend), func(t *testing.T) {
+			result, _ := run(t, src, &Options{Backend: backend})
+
+			value, err := result.Call(context.Background(), "add", 40, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value != int64(42) {
+				t.Errorf("expected add(40, 2) = 42, got %#v", value)
+			}
+
+			value, err = result.Call(context.Background(), "add", "meow", 1.5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value != "meow1.5" {
+				t.Errorf("expected add(\"meow\", 1.5) = \"meow1.5\", got %#v", value)
+			}
+
+			value, err = result.Call(context.Background(), "pair", nil, []int{1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(value, []interface{}{nil, []interface{}{int64(1)}}) {
+				t.Errorf("expected pair(nil, [1]) = [nil, [1]], got %#v", value)
+			}
+
+			if count, ok := result.Get("count"); !ok || count != int64(2) {
+				t.Errorf("expected count = 2, got %#v", count)
+			}
+			if _, ok := result.Get("missing"); ok {
+				t.Errorf("expected missing not to be found")
+			}
+
+			var runtimeErr *object.Error
+			if _, err := result.Call(context.Background(), "fail"); !errors.As(err, &runtimeErr) || runtimeErr.Message != "division by zero" {
+				t.Errorf("expected division by zero error, got %v", err)
+			}
+			if _, err := result.Call(context.Background(), "add", 1); err == nil || err.Error() != "wrong number of arguments: want=2, got=1" {
+				t.Errorf("expected wrong number of arguments error, got %v", err)
+			}
+			if _, err := result.Call(context.Background(), "count"); err == nil || err.Error() != "not a function: INTEGER" {
+				t.Errorf("expected not a function error, got %v", err)
+			}
+			if _, err := result.Call(context.Background(), "missing"); err == nil || err.Error() != "identifier not found: missing" {
+				t.Errorf("expected identifier not found error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUnknownBackend(t *testing.T) {
+	program, err := Compile(`purr 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Run(context.Background(), program, &Options{Backend: "jit"})
+	if err == nil || err.Error() != `unknown backend "jit", want "interpreter" or "vm"` {
+		t.Errorf("expected unknown backend error, got %v", err)
+	}
+}
+
+func TestBackendValidate(t *testing.T) {
+	for _, backend := range []Backend{Interpreter, VM, ""} {
+		if err := backend.Validate(); err != nil {
+			t.Errorf("expected backend %q to be valid, got %v", backend, err)
+		}
+	}
+	if err := Backend("jit").Validate(); err == nil {
+		t.Errorf("expected an error for an unknown backend")
+	}
+}
+
+func TestToGo(t *testing.T) {
+	m := object.NewMap()
+	m.Set(&object.String{Value: "a"}, &object.Integer{Value: 1})
+	m.Set(object.NewBigInteger(new(big.Int).Lsh(big.NewInt(1), 64)), &object.Boolean{Value: true})
+
+	tests := []struct {
+		obj      object.Object
+		expected interface{}
+	}{
+		{&object.Integer{Value: 7}, int64(7)},
+		{&object.Float{Value: 1.5}, 1.5},
+		{&object.String{Value: "cat"}, "cat"},
+		{&object.Boolean{Value: true}, true},
+		{&object.Null{}, nil},
+		{&object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.Null{}}}, []interface{}{int64(1), nil}},
+		{m, map[interface{}]interface{}{"a": int64(1), "18446744073709551616": true}},
+	}
+
+	for _, tt := range tests {
+		if value := ToGo(tt.obj); !reflect.DeepEqual(value, tt.expected) {
+			t.Errorf("ToGo(%s): expected %#v, got %#v", tt.obj.Inspect(), tt.expected, value)
+		}
+	}
+}
+
+func TestToGoCycles(t *testing.T) {
+	array := &object.Array{Elements: []object.Object{&object.Integer{Value: 1}}}
+	array.Elements = append(array.Elements, array)
+	m := object.NewMap()
+	m.Set(&object.String{Value: "self"}, m)
+	m.Set(&object.String{Value: "array"}, array)
+
+	expected := map[interface{}]interface{}{"self": nil, "array": []interface{}{int64(1), nil}}
+	if value := ToGo(m); !reflect.DeepEqual(value, expected) {
+		t.Errorf("expected %#v, got %#v", expected, value)
+	}
+}
+
+func TestToObjectCycles(t *testing.T) {
+	m := map[string]interface{}{}
+	m["self"] = m
+	s := []interface{}{1, nil}
+	s[1] = s
+	var p interface{}
+	p = &p
+
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{m, "cyclic Go value of type map[string]interface {}"},
+		{s, "cyclic Go value of type []interface {}"},
+		{[]interface{}{map[string]interface{}{"m": m}}, "cyclic Go value of type map[string]interface {}"},
+		{p, "cyclic Go value of type *interface {}"},
+	}
+
+	for _, tt := range tests {
+		if _, err := ToObject(tt.value); err == nil || err.Error() != tt.expected {
+			t.Errorf("expected error %q, got %v", tt.expected, err)
+		}
+	}
+
+	// A value that appears twice without containing itself is not a cycle.
+	shared := []int{1}
+	obj, err := ToObject(map[string]interface{}{"a": shared, "b": shared})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.Inspect() != `{"a": [1], "b": [1]}` {
+		t.Errorf("expected %q, got %q", `{"a": [1], "b": [1]}`, obj.Inspect())
+	}
+
+	program, err := Compile(`purr self`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Run(context.Background(), program, &Options{Stdout: io.Discard, Globals: map[string]interface{}{"self": m}})
+	if err == nil || err.Error() != "cannot bind self: cyclic Go value of type map[string]interface {}" {
+		t.Errorf("expected a cyclic value error, got %v", err)
+	}
+}
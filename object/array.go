@@ -0,0 +1,51 @@
+package object
+
+import (
+	"strconv"
+	"strings"
+)
+
+const ARRAY_OBJ = "ARRAY"
+
+type Array struct {
+	Elements []Object
+}
+
+func (a *Array) Type() ObjectType {
+	return ARRAY_OBJ
+}
+
+// Inspect prints the elements between brackets, e.g. [1, "two", 3.0]. An
+// array inside itself prints as [...].
+func (a *Array) Inspect() string {
+	return a.inspect(make(map[Object]bool))
+}
+
+// inspect prints the array, seen holds the collections being printed around it.
+func (a *Array) inspect(seen map[Object]bool) string {
+	if seen[a] {
+		return "[...]"
+	}
+	seen[a] = true
+	defer delete(seen, a)
+
+	elements := make([]string, len(a.Elements))
+	for i, element := range a.Elements {
+		elements[i] = inspectElement(element, seen)
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// inspectElement prints an element of a collection. Strings are quoted, so
+// that [1, "1"] is not mistaken for [1, 1].
+func inspectElement(obj Object, seen map[Object]bool) string {
+	switch obj := obj.(type) {
+	case *String:
+		return strconv.Quote(obj.Value)
+	case *Array:
+		return obj.inspect(seen)
+	case *Map:
+		return obj.inspect(seen)
+	}
+	return obj.Inspect()
+}
@@ -0,0 +1,30 @@
+package object
+
+import "io"
+
+const BUILTIN_OBJ = "BUILTIN"
+
+// Runtime gives built-in functions access to the input and output of the running program.
+type Runtime interface {
+	io.Writer // the standard output
+
+	// Stderr returns where diagnostics are written.
+	Stderr() io.Writer
+
+	// ReadLine reads a line of input, without its line ending.
+	// It returns io.EOF once there is no more input.
+	ReadLine() (string, error)
+}
+
+// BuiltinFunction is the Go implementation of a built-in function. It checks
+// its own arguments, returning an Error if they are invalid.
+type BuiltinFunction func(rt Runtime, args ...Object) Object
+
+// Builtin is a function provided by the language, e.g. len.
+type Builtin struct {
+	Name string
+	Fn   BuiltinFunction
+}
+
+func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
+func (b *Builtin) Inspect() string  { return "builtin " + b.Name }
@@ -0,0 +1,187 @@
+package object
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Builtins is the registry of built-in functions, defined in the global
+// environment of every program.
+var Builtins = []*Builtin{
+	{Name: "len", Fn: builtinLen},
+	{Name: "type", Fn: builtinType},
+	{Name: "str", Fn: builtinStr},
+	{Name: "int", Fn: builtinInt},
+	{Name: "float", Fn: builtinFloat},
+	{Name: "push", Fn: builtinPush},
+	{Name: "keys", Fn: builtinKeys},
+	{Name: "input", Fn: builtinInput},
+}
+
+// newError creates an Error without a location. The interpreter locates it at the call.
+func newError(format string, a ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, a...)}
+}
+
+// checkArity returns an Error if a built-in is not called with the expected number of arguments.
+func checkArity(name string, args []Object, want int) *Error {
+	if len(args) != want {
+		return newError("wrong number of arguments to %s: want=%d, got=%d", name, want, len(args))
+	}
+	return nil
+}
+
+// len(x) returns the number of characters of a string, elements of an array or entries of a map.
+func builtinLen(rt Runtime, args ...Object) Object {
+	if err := checkArity("len", args, 1); err != nil {
+		return err
+	}
+
+	switch arg := args[0].(type) {
+	case *String:
+		return NewInteger(int64(utf8.RuneCountInString(arg.Value)))
+	case *Array:
+		return NewInteger(int64(len(arg.Elements)))
+	case *Map:
+		return NewInteger(int64(arg.Len()))
+	default:
+		return newError("argument to len not supported, got %s", arg.Type())
+	}
+}
+
+// type(x) returns the name of the type of x, e.g. "INTEGER".
+func builtinType(rt Runtime, args ...Object) Object {
+	if err := checkArity("type", args, 1); err != nil {
+		return err
+	}
+	return &String{Value: string(args[0].Type())}
+}
+
+// str(x) returns x as it would be printed.
+func builtinStr(rt Runtime, args ...Object) Object {
+	if err := checkArity("str", args, 1); err != nil {
+		return err
+	}
+	return &String{Value: args[0].Inspect()}
+}
+
+// int(x) converts a number, a boolean or a string to an integer. Floats are
+// truncated toward zero.
+func builtinInt(rt Runtime, args ...Object) Object {
+	if err := checkArity("int", args, 1); err != nil {
+		return err
+	}
+
+	switch arg := args[0].(type) {
+	case *Integer:
+		return arg
+	case *Float:
+		if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) {
+			return newError("could not convert %s to INTEGER", arg.Inspect())
+		}
+		value, _ := big.NewFloat(arg.Value).Int(nil)
+		return NewBigInteger(value)
+	case *Boolean:
+		if arg.Value {
+			return NewInteger(1)
+		}
+		return NewInteger(0)
+	case *String:
+		value, ok := new(big.Int).SetString(strings.TrimSpace(arg.Value), 10)
+		if !ok {
+			return newError("could not convert %q to INTEGER", arg.Value)
+		}
+		return NewBigInteger(value)
+	default:
+		return newError("argument to int not supported, got %s", arg.Type())
+	}
+}
+
+// float(x) converts a number or a string to a float.
+func builtinFloat(rt Runtime, args ...Object) Object {
+	if err := checkArity("float", args, 1); err != nil {
+		return err
+	}
+
+	switch arg := args[0].(type) {
+	case *Integer:
+		if arg.IsBig() {
+			value, _ := new(big.Float).SetInt(arg.Big).Float64()
+			return &Float{Value: value}
+		}
+		return &Float{Value: float64(arg.Value)}
+	case *Float:
+		return arg
+	case *String:
+		value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+		if err != nil {
+			return newError("could not convert %q to FLOAT", arg.Value)
+		}
+		return &Float{Value: value}
+	default:
+		return newError("argument to float not supported, got %s", arg.Type())
+	}
+}
+
+// push(array, x) appends x to the end of an array, and returns the array.
+func builtinPush(rt Runtime, args ...Object) Object {
+	if err := checkArity("push", args, 2); err != nil {
+		return err
+	}
+
+	array, ok := args[0].(*Array)
+	if !ok {
+		return newError("first argument to push must be ARRAY, got %s", args[0].Type())
+	}
+
+	array.Elements = append(array.Elements, args[1])
+	return array
+}
+
+// keys(map) returns the keys of a map, in insertion order.
+func builtinKeys(rt Runtime, args ...Object) Object {
+	if err := checkArity("keys", args, 1); err != nil {
+		return err
+	}
+
+	m, ok := args[0].(*Map)
+	if !ok {
+		return newError("argument to keys must be MAP, got %s", args[0].Type())
+	}
+
+	pairs := m.Pairs()
+	keys := make([]Object, len(pairs))
+	for i, pair := range pairs {
+		keys[i] = pair.Key
+	}
+	return &Array{Elements: keys}
+}
+
+// input() reads a line of input, and input(prompt) prints a prompt before.
+// It returns null once there is no more input.
+func builtinInput(rt Runtime, args ...Object) Object {
+	if len(args) > 1 {
+		return newError("wrong number of arguments to input: want=0 or 1, got=%d", len(args))
+	}
+
+	if len(args) == 1 {
+		if _, err := io.WriteString(rt, args[0].Inspect()); err != nil {
+			return newError("could not print the prompt: %s", err)
+		}
+	}
+
+	line, err := rt.ReadLine()
+	if errors.Is(err, io.EOF) {
+		return &Null{}
+	}
+	if err != nil {
+		return newError("could not read input: %s", err)
+	}
+	return &String{Value: line}
+}
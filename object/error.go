@@ -0,0 +1,29 @@
+package object
+
+import "github.com/AlyxPink/meowlang/token"
+
+const ERROR_OBJ = "ERROR"
+
+// Error is a runtime error. It unwinds the program up to the caller of
+// Interpret and also implements the Go error interface.
+type Error struct {
+	Message string
+	Token   token.Token // where the error occurred
+	Err     error       // the Go error that caused it, if any
+}
+
+func (e *Error) Type() ObjectType { return ERROR_OBJ }
+func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
+
+// Error returns the message prefixed by its position, e.g. "3:7: division by zero".
+func (e *Error) Error() string {
+	if !e.Token.Pos.IsValid() {
+		return e.Message
+	}
+	return e.Token.Pos.String() + ": " + e.Message
+}
+
+// Unwrap returns the Go error that caused the error, if any, for errors.Is and errors.As.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
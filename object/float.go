@@ -0,0 +1,42 @@
+package object
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const FLOAT_OBJ = "FLOAT"
+
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType {
+	return FLOAT_OBJ
+}
+
+// Inspect prints the shortest representation that reads back as the same value.
+// It always shows it is a float, e.g. 3.0 rather than 3, and uses an exponent
+// only for very large or very small values, e.g. 1e+21 or 1e-05.
+func (f *Float) Inspect() string {
+	switch {
+	case math.IsNaN(f.Value):
+		return "NaN"
+	case math.IsInf(f.Value, 1):
+		return "Infinity"
+	case math.IsInf(f.Value, -1):
+		return "-Infinity"
+	}
+
+	abs := math.Abs(f.Value)
+	if abs != 0 && (abs < 1e-4 || abs >= 1e21) {
+		return strconv.FormatFloat(f.Value, 'e', -1, 64)
+	}
+
+	str := strconv.FormatFloat(f.Value, 'f', -1, 64)
+	if !strings.Contains(str, ".") {
+		str += ".0"
+	}
+	return str
+}
@@ -31,9 +31,7 @@ func (f *Function) Inspect() string {
 	out.WriteString("meow")
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
-	out.WriteString(") {\n")
-	out.WriteString(f.Body.TokenLiteral())
-	out.WriteString("\n}")
+	out.WriteString(") { ... }")
 
 	return out.String()
 }
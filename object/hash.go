@@ -0,0 +1,34 @@
+package object
+
+// HashKey identifies a map key by value, so that two equal keys, e.g. two
+// strings with the same content, find the same entry. It is comparable, so
+// it can be used as a Go map key.
+type HashKey struct {
+	Type ObjectType
+	Int  int64  // the value of integers and booleans
+	Str  string // the value of strings, and of integers that do not fit in an int64
+}
+
+// Hashable is an object that can be used as a map key.
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+func (i *Integer) HashKey() HashKey {
+	if i.Big != nil {
+		return HashKey{Type: i.Type(), Str: i.Big.String()}
+	}
+	return HashKey{Type: i.Type(), Int: i.Value}
+}
+
+func (s *String) HashKey() HashKey {
+	return HashKey{Type: s.Type(), Str: s.Value}
+}
+
+func (b *Boolean) HashKey() HashKey {
+	if b.Value {
+		return HashKey{Type: b.Type(), Int: 1}
+	}
+	return HashKey{Type: b.Type(), Int: 0}
+}
@@ -1,11 +1,62 @@
 package object
 
-import "fmt"
+import (
+	"fmt"
+	"math/big"
+)
 
 const INTEGER_OBJ = "INTEGER"
 
+// Integer is an integer of any size. It holds its value in Value while it fits
+// in an int64, and promotes to an arbitrary-precision Big value beyond that.
 type Integer struct {
 	Value int64
+	Big   *big.Int // set only when the value does not fit in an int64
+}
+
+// Small integers are created once and shared by NewInteger, as Integers are
+// never modified, so that arithmetic on them does not allocate.
+const (
+	minSmallInteger = -256
+	maxSmallInteger = 1024
+)
+
+var smallIntegers = func() []Integer {
+	integers := make([]Integer, maxSmallInteger-minSmallInteger+1)
+	for i := range integers {
+		integers[i].Value = int64(i + minSmallInteger)
+	}
+	return integers
+}()
+
+// NewInteger creates an Integer from an int64.
+func NewInteger(value int64) *Integer {
+	if value >= minSmallInteger && value <= maxSmallInteger {
+		return &smallIntegers[value-minSmallInteger]
+	}
+	return &Integer{Value: value}
+}
+
+// NewBigInteger creates an Integer from a big.Int, only keeping it as a big
+// value if it does not fit in an int64.
+func NewBigInteger(value *big.Int) *Integer {
+	if value.IsInt64() {
+		return NewInteger(value.Int64())
+	}
+	return &Integer{Big: value}
+}
+
+// IsBig reports whether the value does not fit in an int64.
+func (i *Integer) IsBig() bool {
+	return i.Big != nil
+}
+
+// BigValue returns the value as a big.Int, which may be modified freely.
+func (i *Integer) BigValue() *big.Int {
+	if i.Big != nil {
+		return new(big.Int).Set(i.Big)
+	}
+	return big.NewInt(i.Value)
 }
 
 func (i *Integer) Type() ObjectType {
@@ -13,5 +64,8 @@ func (i *Integer) Type() ObjectType {
 }
 
 func (i *Integer) Inspect() string {
+	if i.Big != nil {
+		return i.Big.String()
+	}
 	return fmt.Sprintf("%d", i.Value)
 }
@@ -0,0 +1,95 @@
+package object
+
+import "strings"
+
+const MAP_OBJ = "MAP"
+
+// MapPair is an entry of a map.
+type MapPair struct {
+	Key   Object
+	Value Object
+}
+
+// Map associates hashable keys to values, remembering the order in which the
+// keys were first inserted.
+type Map struct {
+	pairs map[HashKey]MapPair
+	order []HashKey
+}
+
+// NewMap creates an empty map.
+func NewMap() *Map {
+	return &Map{pairs: make(map[HashKey]MapPair)}
+}
+
+func (m *Map) Type() ObjectType {
+	return MAP_OBJ
+}
+
+// Inspect prints the entries in insertion order, e.g. {"name": "Mochi", "age": 3}.
+// A map inside itself prints as {...}.
+func (m *Map) Inspect() string {
+	return m.inspect(make(map[Object]bool))
+}
+
+// inspect prints the map, seen holds the collections being printed around it.
+func (m *Map) inspect(seen map[Object]bool) string {
+	if seen[m] {
+		return "{...}"
+	}
+	seen[m] = true
+	defer delete(seen, m)
+
+	pairs := make([]string, 0, len(m.order))
+	for _, pair := range m.Pairs() {
+		pairs = append(pairs, inspectElement(pair.Key, seen)+": "+inspectElement(pair.Value, seen))
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// Get returns the value of a key, and whether the key is in the map.
+func (m *Map) Get(key Hashable) (Object, bool) {
+	pair, ok := m.pairs[key.HashKey()]
+	return pair.Value, ok
+}
+
+// Set sets the value of a key. A new key is added after the existing ones, an
+// existing key keeps its place.
+func (m *Map) Set(key Hashable, value Object) {
+	hashKey := key.HashKey()
+	if _, ok := m.pairs[hashKey]; !ok {
+		m.order = append(m.order, hashKey)
+	}
+	m.pairs[hashKey] = MapPair{Key: key, Value: value}
+}
+
+// Delete removes a key from the map, and reports whether it was in the map.
+func (m *Map) Delete(key Hashable) bool {
+	hashKey := key.HashKey()
+	if _, ok := m.pairs[hashKey]; !ok {
+		return false
+	}
+
+	delete(m.pairs, hashKey)
+	for i, k := range m.order {
+		if k == hashKey {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Len returns the number of entries.
+func (m *Map) Len() int {
+	return len(m.order)
+}
+
+// Pairs returns the entries in insertion order.
+func (m *Map) Pairs() []MapPair {
+	pairs := make([]MapPair, len(m.order))
+	for i, hashKey := range m.order {
+		pairs[i] = m.pairs[hashKey]
+	}
+	return pairs
+}
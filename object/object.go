@@ -1,5 +1,7 @@
 package object
 
+import "sort"
+
 type ObjectType string
 
 type Object interface {
@@ -7,14 +9,17 @@ type Object interface {
 	Inspect() string
 }
 
-// For variable storage
+// For variable storage. Each variable has a slot, so that the vm can look up
+// names once, when it starts running a program, and then use their slots.
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	slots  map[string]int // the slot of each name
+	values []Object       // the value of each slot, nil while it is not defined
+	outer  *Environment
+	shared bool // slots belongs to compiled code, it is copied before adding a name
 }
 
 func NewEnvironment() *Environment {
-	return &Environment{store: make(map[string]Object)}
+	return &Environment{slots: make(map[string]int)}
 }
 
 // Creates a new enclosed environment with an outer environment
@@ -24,17 +29,102 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 	return env
 }
 
+// NewSlotEnvironment creates an environment enclosed by outer, with the given
+// slots for the variables of a compiled function, all undefined. slots is
+// not modified, so the calls of a function can share it.
+func NewSlotEnvironment(outer *Environment, slots map[string]int, size int) *Environment {
+	return &Environment{slots: slots, values: make([]Object, size), outer: outer, shared: true}
+}
+
 // Retrieves a variable's value
 func (e *Environment) Get(name string) (Object, bool) {
-	obj, ok := e.store[name]
-	if !ok && e.outer != nil {
-		obj, ok = e.outer.Get(name)
+	if slot, ok := e.slots[name]; ok && e.values[slot] != nil {
+		return e.values[slot], true
+	}
+	if e.outer != nil {
+		return e.outer.Get(name)
 	}
-	return obj, ok
+	return nil, false
 }
 
 // Sets a variable's value
 func (e *Environment) Set(name string, val Object) Object {
-	e.store[name] = val
+	e.values[e.Slot(name)] = val
 	return val
 }
+
+// Updates an existing variable in the closest environment that defines it.
+// It reports false, and changes nothing, if the variable is not defined.
+func (e *Environment) Assign(name string, val Object) (Object, bool) {
+	if slot, ok := e.slots[name]; ok && e.values[slot] != nil {
+		e.values[slot] = val
+		return val, true
+	}
+	if e.outer != nil {
+		return e.outer.Assign(name, val)
+	}
+	return nil, false
+}
+
+// Slot returns the slot of a name in this environment, adding an undefined
+// variable for it if there is none yet.
+func (e *Environment) Slot(name string) int {
+	if slot, ok := e.slots[name]; ok {
+		return slot
+	}
+	if e.shared {
+		slots := make(map[string]int, len(e.slots)+1)
+		for name, slot := range e.slots {
+			slots[name] = slot
+		}
+		e.slots, e.shared = slots, false
+	}
+	e.slots[name] = len(e.values)
+	e.values = append(e.values, nil)
+	return e.slots[name]
+}
+
+// GetSlot retrieves the variable in a slot of this environment, or the
+// variable with the same name in the outer environments if it is not defined.
+func (e *Environment) GetSlot(slot int, name string) (Object, bool) {
+	if val := e.values[slot]; val != nil {
+		return val, true
+	}
+	if e.outer != nil {
+		return e.outer.Get(name)
+	}
+	return nil, false
+}
+
+// SetSlot sets the variable in a slot of this environment.
+func (e *Environment) SetSlot(slot int, val Object) {
+	e.values[slot] = val
+}
+
+// AssignSlot updates the variable in a slot of this environment, or the
+// variable with the same name in the outer environments if it is not
+// defined, like Assign.
+func (e *Environment) AssignSlot(slot int, name string, val Object) bool {
+	if e.values[slot] != nil {
+		e.values[slot] = val
+		return true
+	}
+	if e.outer != nil {
+		_, ok := e.outer.Assign(name, val)
+		return ok
+	}
+	return false
+}
+
+// Names returns the names of the variables defined in this environment, not
+// in its outer ones, in alphabetical order.
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.slots))
+	for name, slot := range e.slots {
+		if e.values[slot] != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,15 @@
+package object
+
+import "github.com/AlyxPink/meowlang/token"
+
+const RETURN_VALUE_OBJ = "RETURN_VALUE"
+
+// ReturnValue wraps the value of a 'claw' statement while it unwinds
+// through nested blocks up to the function call.
+type ReturnValue struct {
+	Value Object
+	Token token.Token // the token.CLAW token, to locate a 'claw' outside of a function
+}
+
+func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
+func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
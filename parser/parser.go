@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"errors"
+	"math/big"
 	"strconv"
 
 	"github.com/AlyxPink/meowlang/ast"
@@ -17,6 +19,7 @@ const (
 	PRODUCT     // *
 	PREFIX      // -X or !X
 	CALL        // myFunction(X)
+	INDEX       // array[index]
 )
 
 // precedences maps token types to their precedence levels.
@@ -25,11 +28,14 @@ var precedences = map[token.TokenType]int{
 	token.NOT_EQ:   EQUALS,
 	token.LT:       LESSGREATER,
 	token.GT:       LESSGREATER,
+	token.LT_EQ:    LESSGREATER,
+	token.GT_EQ:    LESSGREATER,
 	token.PLUS:     SUM,
 	token.MINUS:    SUM,
 	token.SLASH:    PRODUCT,
 	token.ASTERISK: PRODUCT,
 	token.LPAREN:   CALL,
+	token.LBRACKET: INDEX,
 }
 
 // Parser represents a parser for the MeowLang programming language.
@@ -47,37 +53,99 @@ func NewParser(tokens []token.Token) *Parser {
 	}
 }
 
+// Errors returns the errors encountered while parsing.
+func (p *Parser) Errors() []string {
+	return p.errors
+}
+
 // ParseProgram parses the entire input and returns the root of the AST.
+// The program must not be run if Errors is not empty.
 func (p *Parser) ParseProgram() *ast.Program {
 	program := &ast.Program{}
 	program.Statements = []ast.Statement{}
 
 	for !p.isAtEnd() {
-		stmt := p.parseStatement()
-		if stmt != nil {
+		if stmt, ok := p.parseStatementOrSynchronize(false); ok {
 			program.Statements = append(program.Statements, stmt)
-		} else {
-			p.advance() // Advance only if no valid statement was parsed to avoid infinite loop
 		}
 	}
 
 	return program
 }
 
+// parseStatementOrSynchronize parses a single statement. If it fails, the
+// parser skips to the start of the next statement so parsing can go on and
+// report further errors. Inside a block, it also stops at the closing '}'.
+func (p *Parser) parseStatementOrSynchronize(inBlock bool) (ast.Statement, bool) {
+	start, errorCount := p.current, len(p.errors)
+
+	stmt := p.parseStatement()
+	if len(p.errors) == errorCount {
+		return stmt, true
+	}
+
+	if p.current == start {
+		p.advance() // Always make progress to avoid an infinite loop
+	}
+	p.synchronize(inBlock)
+
+	return nil, false
+}
+
 // parseStatement parses a single statement.
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.peek().Type {
 	case token.LICK:
 		return p.parseAssignStatement()
 	case token.MEOW:
-		return p.parseFunctionStatement()
+		// 'meow' followed by a name declares a function, otherwise it is a function literal
+		if p.peekNext().Type == token.IDENT {
+			return p.parseFunctionStatement()
+		}
+		return p.parseExpressionStatement()
 	case token.CLAW:
 		return p.parseReturnStatement()
 	case token.PURR:
 		return p.parsePrintStatement()
+	case token.HISS:
+		return p.parseIfStatement()
+	case token.SCRATCH:
+		return p.parseWhileStatement()
+	case token.NAP:
+		return p.parseNapStatement()
+	case token.SHOO:
+		return p.parseDeleteStatement()
+	case token.IDENT:
+		if p.peekNext().Type == token.ASSIGN {
+			return p.parseReassignStatement()
+		}
+		return p.parseExpressionStatement()
 	default:
+		return p.parseExpressionStatement()
+	}
+}
+
+// parseExpressionStatement parses an expression used as a statement, e.g. a
+// function call, or an assignment to an element, e.g. xs[0] = 1.
+func (p *Parser) parseExpressionStatement() ast.Statement {
+	stmt := &ast.ExpressionStatement{
+		Token: p.peek(),
+	}
+
+	stmt.Expression = p.parseExpression(LOWEST)
+	if stmt.Expression == nil {
 		return nil
 	}
+
+	if p.peek().Type == token.ASSIGN {
+		return p.parseIndexAssignStatement(stmt.Expression)
+	}
+
+	if p.peek().Type == token.SEMICOLON {
+		p.advance() // consume optional semicolon token
+	}
+
+	return stmt
 }
 
 // parseAssignStatement parses an assignment statement.
@@ -107,6 +175,75 @@ func (p *Parser) parseAssignStatement() *ast.AssignStatement {
 	return stmt
 }
 
+// parseReassignStatement parses an assignment to an existing variable, without 'lick'.
+func (p *Parser) parseReassignStatement() *ast.ReassignStatement {
+	name := p.parseIdentifier() // consume identifier token
+
+	stmt := &ast.ReassignStatement{
+		Token: p.advance(), // consume assign token
+		Name:  name,
+	}
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peek().Type == token.SEMICOLON {
+		p.advance() // consume optional semicolon token
+	}
+
+	return stmt
+}
+
+// parseIndexAssignStatement parses an assignment to an element of a collection,
+// once its target is parsed, e.g. xs[0] = 1.
+func (p *Parser) parseIndexAssignStatement(target ast.Expression) ast.Statement {
+	index, ok := target.(*ast.IndexExpression)
+	if !ok {
+		p.errorAt(p.peek(), "cannot assign to this expression, only to a variable or an element")
+		return nil
+	}
+
+	stmt := &ast.IndexAssignStatement{
+		Token:  p.advance(), // consume assign token
+		Target: index,
+	}
+
+	stmt.Value = p.parseExpression(LOWEST)
+	if stmt.Value == nil {
+		return nil
+	}
+
+	if p.peek().Type == token.SEMICOLON {
+		p.advance() // consume optional semicolon token
+	}
+
+	return stmt
+}
+
+// parseDeleteStatement parses a 'shoo' statement, removing an element from a collection.
+func (p *Parser) parseDeleteStatement() ast.Statement {
+	stmt := &ast.DeleteStatement{
+		Token: p.advance(), // consume 'shoo' token
+	}
+
+	target := p.parseExpression(LOWEST)
+	if target == nil {
+		return nil
+	}
+
+	index, ok := target.(*ast.IndexExpression)
+	if !ok {
+		p.errorAt(stmt.Token, "shoo expects an element to remove, e.g. shoo m[\"key\"]")
+		return nil
+	}
+	stmt.Target = index
+
+	if p.peek().Type == token.SEMICOLON {
+		p.advance() // consume optional semicolon token
+	}
+
+	return stmt
+}
+
 // parseFunctionStatement parses a function definition statement.
 func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
 	stmt := &ast.FunctionStatement{
@@ -117,35 +254,63 @@ func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
 		return nil
 	}
 	stmt.Name = &ast.Identifier{
-		Token: p.peek(),
+		Token: p.previous(),
 		Value: p.previous().Literal,
 	}
 
-	if !p.expectPeek(token.LPAREN) {
+	stmt.Parameters, stmt.Body = p.parseFunction()
+	if stmt.Body == nil {
 		return nil
 	}
 
-	stmt.Parameters = p.parseFunctionParameters()
+	return stmt
+}
 
-	if !p.expectPeek(token.LBRACE) {
+// parseFunctionLiteral parses an anonymous function, e.g. meow(x) { claw x * 2 }.
+func (p *Parser) parseFunctionLiteral() ast.Expression {
+	lit := &ast.FunctionLiteral{
+		Token: p.advance(), // consume 'meow' token
+	}
+
+	lit.Parameters, lit.Body = p.parseFunction()
+	if lit.Body == nil {
 		return nil
 	}
 
-	stmt.Body = p.parseBlockStatement()
+	return lit
+}
 
-	return stmt
+// parseFunction parses the parameters and the body of a function, after
+// 'meow' and its name if any. The body is nil if they are invalid.
+func (p *Parser) parseFunction() ([]*ast.Identifier, *ast.BlockStatement) {
+	if !p.expectPeek(token.LPAREN) {
+		return nil, nil
+	}
+
+	parameters := p.parseFunctionParameters()
+	if parameters == nil {
+		return nil, nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil, nil
+	}
+
+	return parameters, p.parseBlockStatement()
 }
 
-// parseFunctionParameters parses the parameters of a function.
+// parseFunctionParameters parses the parameters of a function. It returns nil if they are invalid.
 func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	var parameters []*ast.Identifier
+	parameters := []*ast.Identifier{}
 
 	if p.peek().Type == token.RPAREN {
 		p.advance()
 		return parameters
 	}
 
-	p.advance() // consume first parameter
+	if !p.expectPeek(token.IDENT) { // consume first parameter
+		return nil
+	}
 	param := &ast.Identifier{
 		Token: p.previous(),
 		Value: p.previous().Literal,
@@ -155,7 +320,9 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	for p.peek().Type == token.COMMA {
 		p.advance() // consume ',' token
 
-		p.advance() // consume next parameter
+		if !p.expectPeek(token.IDENT) { // consume next parameter
+			return nil
+		}
 		param := &ast.Identifier{
 			Token: p.previous(),
 			Value: p.previous().Literal,
@@ -173,22 +340,20 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 // parseBlockStatement parses a block of statements enclosed in curly braces.
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{
-		Token: p.peek(),
+		Token: p.previous(), // the '{' token, consumed by the caller
 	}
 	block.Statements = []ast.Statement{}
 
 	for !p.isAtEnd() && p.peek().Type != token.RBRACE {
-		stmt := p.parseStatement()
-		if stmt != nil {
+		if stmt, ok := p.parseStatementOrSynchronize(true); ok {
 			block.Statements = append(block.Statements, stmt)
-		} else {
-			p.advance() // Advance to avoid infinite loop if no valid statement was parsed
 		}
 	}
 
 	if !p.expectPeek(token.RBRACE) {
 		return nil
 	}
+	block.RBrace = p.previous()
 
 	return block
 }
@@ -199,7 +364,12 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 		Token: p.advance(), // consume 'claw' token
 	}
 
-	stmt.ReturnValue = p.parseExpression(LOWEST)
+	// A bare 'claw' returns null
+	switch p.peek().Type {
+	case token.RBRACE, token.SEMICOLON, token.EOF:
+	default:
+		stmt.ReturnValue = p.parseExpression(LOWEST)
+	}
 
 	if p.peek().Type == token.SEMICOLON {
 		p.advance()
@@ -223,32 +393,137 @@ func (p *Parser) parsePrintStatement() *ast.PrintStatement {
 	return stmt
 }
 
+// parseIfStatement parses a conditional statement, including any 'growl' and
+// 'growl hiss' branches.
+func (p *Parser) parseIfStatement() *ast.IfStatement {
+	stmt := &ast.IfStatement{
+		Token: p.advance(), // consume 'hiss' token
+	}
+
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Consequence = p.parseBlockStatement()
+	if stmt.Consequence == nil {
+		return nil
+	}
+
+	if p.peek().Type != token.GROWL {
+		return stmt
+	}
+	p.advance() // consume 'growl' token
+
+	// 'growl hiss' chains are parsed as an alternative block holding the nested conditional
+	if p.peek().Type == token.HISS {
+		nested := p.parseIfStatement()
+		if nested == nil {
+			return nil
+		}
+		stmt.Alternative = &ast.BlockStatement{
+			Token:      nested.Token,
+			Statements: []ast.Statement{nested},
+		}
+		return stmt
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Alternative = p.parseBlockStatement()
+	if stmt.Alternative == nil {
+		return nil
+	}
+
+	return stmt
+}
+
+// parseWhileStatement parses a 'scratch' loop.
+func (p *Parser) parseWhileStatement() *ast.WhileStatement {
+	stmt := &ast.WhileStatement{
+		Token: p.advance(), // consume 'scratch' token
+	}
+
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+	if stmt.Body == nil {
+		return nil
+	}
+
+	return stmt
+}
+
+// parseNapStatement parses a 'nap' statement, e.g. 'nap(1)'.
+func (p *Parser) parseNapStatement() *ast.NapStatement {
+	stmt := &ast.NapStatement{
+		Token: p.advance(), // consume 'nap' token
+	}
+
+	stmt.Duration = p.parseExpression(LOWEST)
+
+	if p.peek().Type == token.SEMICOLON {
+		p.advance() // consume optional semicolon token
+	}
+
+	return stmt
+}
+
 // parseCallExpression parses a function call expression.
 func (p *Parser) parseCallExpression(function ast.Expression) *ast.CallExpression {
 	exp := &ast.CallExpression{
-		Token:    p.peek(),
+		Token:    p.advance(), // consume '(' token
 		Function: function,
 	}
 
 	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	if exp.Arguments == nil {
+		return nil
+	}
+	exp.RParen = p.previous()
+	return exp
+}
+
+// parseIndexExpression parses an index expression, e.g. xs[0].
+func (p *Parser) parseIndexExpression(left ast.Expression) *ast.IndexExpression {
+	exp := &ast.IndexExpression{
+		Token: p.advance(), // consume '[' token
+		Left:  left,
+	}
+
+	exp.Index = p.parseExpression(LOWEST)
+	if exp.Index == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	exp.RBracket = p.previous()
 	return exp
 }
 
 // parseExpressionList parses a list of expressions, separated by commas, and ending with a specified token.
+// The opening token of the list must already be consumed.
 func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
-	var list []ast.Expression
+	list := []ast.Expression{}
 
 	if p.peek().Type == end {
 		p.advance()
 		return list
 	}
 
-	p.advance()
 	list = append(list, p.parseExpression(LOWEST))
 
 	for p.peek().Type == token.COMMA {
 		p.advance() // consume ','
-		p.advance()
 		list = append(list, p.parseExpression(LOWEST))
 	}
 
@@ -264,20 +539,31 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 func (p *Parser) parseExpression(precedence int) ast.Expression {
 	// Parse the left-hand side of the expression
 	leftExp := p.parsePrimary()
+	if leftExp == nil {
+		return nil
+	}
 
 	// Handle infix operators and function calls
 	for !p.isAtEnd() && precedence < p.peekPrecedence() {
 		infix := p.peek()
 
-		if infix.Type != token.PLUS && infix.Type != token.MINUS &&
-			infix.Type != token.SLASH && infix.Type != token.ASTERISK &&
-			infix.Type != token.LPAREN {
+		if !isInfixOperator(infix.Type) && infix.Type != token.LPAREN && infix.Type != token.LBRACKET {
 			return leftExp
 		}
 
-		// Handle function calls
+		// Handle function calls and index expressions
 		if infix.Type == token.LPAREN {
-			leftExp = p.parseCallExpression(leftExp)
+			call := p.parseCallExpression(leftExp)
+			if call == nil {
+				return nil
+			}
+			leftExp = call
+		} else if infix.Type == token.LBRACKET {
+			index := p.parseIndexExpression(leftExp)
+			if index == nil {
+				return nil
+			}
+			leftExp = index
 		} else {
 			// Handle infix expressions
 			p.advance()
@@ -294,10 +580,24 @@ func (p *Parser) parsePrimary() ast.Expression {
 	switch p.peek().Type {
 	case token.INT:
 		return p.parseIntegerLiteral()
+	case token.FLOAT:
+		return p.parseFloatLiteral()
 	case token.STRING:
 		return p.parseStringLiteral()
+	case token.TEMPLATE_HEAD:
+		return p.parseInterpolatedString()
 	case token.IDENT:
 		return p.parseIdentifier()
+	case token.TRUE, token.FALSE:
+		return p.parseBooleanLiteral()
+	case token.LBRACKET:
+		return p.parseArrayLiteral()
+	case token.LBRACE:
+		return p.parseMapLiteral()
+	case token.MEOW:
+		return p.parseFunctionLiteral()
+	case token.MINUS, token.BANG:
+		return p.parsePrefixExpression()
 	case token.LPAREN:
 		p.advance()
 		expr := p.parseExpression(LOWEST)
@@ -306,19 +606,47 @@ func (p *Parser) parsePrimary() ast.Expression {
 		}
 		return expr
 	default:
+		p.errorAt(p.peek(), "unexpected token "+tokenDescription(p.peek()))
 		return nil
 	}
 }
 
 // parseIntegerLiteral parses an integer literal.
-func (p *Parser) parseIntegerLiteral() *ast.IntegerLiteral {
+func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{
 		Token: p.advance(),
 	}
 
 	value, err := strconv.ParseInt(lit.Token.Literal, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		// Too large for an int64, keep it as an arbitrary-precision integer
+		if bigValue, ok := new(big.Int).SetString(lit.Token.Literal, 0); ok {
+			lit.Big = bigValue
+			return lit
+		}
+	}
+	if err != nil {
+		p.errorAt(lit.Token, "could not parse "+lit.Token.Literal+" as integer")
+		return nil
+	}
+
+	lit.Value = value
+	return lit
+}
+
+// parseFloatLiteral parses a floating-point literal.
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{
+		Token: p.advance(),
+	}
+
+	value, err := strconv.ParseFloat(lit.Token.Literal, 64)
+	if errors.Is(err, strconv.ErrSyntax) {
+		// A malformed literal, e.g. 1e, is already reported by the lexer
+		return lit
+	}
 	if err != nil {
-		p.errors = append(p.errors, "could not parse "+lit.Token.Literal+" as integer")
+		p.errorAt(lit.Token, "could not parse "+lit.Token.Literal+" as float")
 		return nil
 	}
 
@@ -326,6 +654,58 @@ func (p *Parser) parseIntegerLiteral() *ast.IntegerLiteral {
 	return lit
 }
 
+// parseArrayLiteral parses an array literal, e.g. [1, 2, 3].
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	array := &ast.ArrayLiteral{
+		Token: p.advance(), // consume '[' token
+	}
+
+	array.Elements = p.parseExpressionList(token.RBRACKET)
+	if array.Elements == nil {
+		return nil
+	}
+	array.RBracket = p.previous()
+	return array
+}
+
+// parseMapLiteral parses a map literal, e.g. {"name": "Mochi", "age": 3}.
+func (p *Parser) parseMapLiteral() ast.Expression {
+	lit := &ast.MapLiteral{
+		Token: p.advance(), // consume '{' token
+		Pairs: []ast.MapLiteralPair{},
+	}
+
+	for p.peek().Type != token.RBRACE {
+		key := p.parseExpression(LOWEST)
+		if key == nil {
+			return nil
+		}
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		value := p.parseExpression(LOWEST)
+		if value == nil {
+			return nil
+		}
+
+		lit.Pairs = append(lit.Pairs, ast.MapLiteralPair{Key: key, Value: value})
+
+		switch p.peek().Type {
+		case token.COMMA:
+			p.advance()
+		case token.RBRACE:
+		default:
+			p.errorAt(p.peek(), "expected ',' or '}' after a map entry, got "+tokenDescription(p.peek())+" instead")
+			return nil
+		}
+	}
+
+	lit.RBrace = p.advance() // consume '}' token
+	return lit
+}
+
 // parseStringLiteral parses a string literal.
 func (p *Parser) parseStringLiteral() *ast.StringLiteral {
 	lit := &ast.StringLiteral{
@@ -335,6 +715,52 @@ func (p *Parser) parseStringLiteral() *ast.StringLiteral {
 	return lit
 }
 
+// parseBooleanLiteral parses a 'true' or 'false' literal.
+func (p *Parser) parseBooleanLiteral() *ast.BooleanLiteral {
+	lit := &ast.BooleanLiteral{
+		Token: p.advance(),
+		Value: p.previous().Type == token.TRUE,
+	}
+	return lit
+}
+
+// parseInterpolatedString parses a string with embedded expressions, from
+// its token.TEMPLATE_HEAD to its token.TEMPLATE_TAIL.
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	lit := &ast.InterpolatedString{
+		Token: p.advance(), // consume the head of the string
+	}
+	lit.Parts = appendStringPart(lit.Parts, lit.Token)
+
+	for {
+		exp := p.parseExpression(LOWEST)
+		if exp == nil {
+			return nil
+		}
+		lit.Parts = append(lit.Parts, exp)
+
+		switch p.peek().Type {
+		case token.TEMPLATE_MIDDLE:
+			lit.Parts = appendStringPart(lit.Parts, p.advance())
+		case token.TEMPLATE_TAIL:
+			lit.Tail = p.advance()
+			lit.Parts = appendStringPart(lit.Parts, lit.Tail)
+			return lit
+		default:
+			p.errorAt(p.peek(), "expected '}' to end the embedded expression, got "+tokenDescription(p.peek())+" instead")
+			return nil
+		}
+	}
+}
+
+// appendStringPart appends the literal part of an interpolated string, unless it is empty.
+func appendStringPart(parts []ast.Expression, tok token.Token) []ast.Expression {
+	if tok.Literal == "" {
+		return parts
+	}
+	return append(parts, &ast.StringLiteral{Token: tok, Value: tok.Literal})
+}
+
 // parseIdentifier parses an identifier.
 func (p *Parser) parseIdentifier() *ast.Identifier {
 	ident := &ast.Identifier{
@@ -344,6 +770,21 @@ func (p *Parser) parseIdentifier() *ast.Identifier {
 	return ident
 }
 
+// parsePrefixExpression parses a prefix expression, e.g. '-x' or '!cond'.
+func (p *Parser) parsePrefixExpression() ast.Expression {
+	exp := &ast.PrefixExpression{
+		Token:    p.advance(), // consume the operator token
+		Operator: p.previous().Literal,
+	}
+
+	exp.Right = p.parseExpression(PREFIX)
+	if exp.Right == nil {
+		return nil
+	}
+
+	return exp
+}
+
 // parseInfixExpression parses an infix expression.
 func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	exp := &ast.InfixExpression{
@@ -360,6 +801,17 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 
 // Helper methods
 
+// isInfixOperator reports whether a token is a binary operator handled by parseInfixExpression.
+func isInfixOperator(t token.TokenType) bool {
+	switch t {
+	case token.PLUS, token.MINUS, token.ASTERISK, token.SLASH,
+		token.EQ, token.NOT_EQ, token.LT, token.GT, token.LT_EQ, token.GT_EQ:
+		return true
+	default:
+		return false
+	}
+}
+
 // advance advances the parser to the next token.
 func (p *Parser) advance() token.Token {
 	tok := p.tokens[p.current]
@@ -377,17 +829,79 @@ func (p *Parser) peek() token.Token {
 	return p.tokens[p.current]
 }
 
+// peekNext returns the token after the current one without advancing the parser.
+func (p *Parser) peekNext() token.Token {
+	if p.current+1 >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.current+1]
+}
+
 // expectPeek checks if the next token is of the expected type and advances the parser if it is.
 func (p *Parser) expectPeek(t token.TokenType) bool {
 	if p.peek().Type == t {
 		p.advance()
 		return true
 	} else {
-		p.errors = append(p.errors, "expected next token to be "+string(t)+", got "+string(p.peek().Type)+" instead")
+		p.errorAt(p.peek(), "expected next token to be "+string(t)+", got "+tokenDescription(p.peek())+" instead")
 		return false
 	}
 }
 
+// errorAt records an error located at the given token, e.g. "3:7: unexpected token ')'".
+func (p *Parser) errorAt(tok token.Token, msg string) {
+	p.errors = append(p.errors, tok.Pos.String()+": "+msg)
+}
+
+// synchronize skips tokens until the start of the next statement, after a
+// statement failed to parse.
+func (p *Parser) synchronize(inBlock bool) {
+	for !p.isAtEnd() {
+		switch p.peek().Type {
+		case token.LICK, token.MEOW, token.CLAW, token.PURR, token.HISS, token.SCRATCH, token.NAP, token.SHOO:
+			return
+		case token.RBRACE:
+			if inBlock {
+				return
+			}
+		case token.SEMICOLON:
+			p.advance()
+			return
+		case token.LBRACE:
+			p.skipBlock() // the body of the broken statement
+			continue
+		}
+		p.advance()
+	}
+}
+
+// skipBlock skips a block and any blocks nested in it, up to its matching '}'.
+func (p *Parser) skipBlock() {
+	depth := 0
+	for !p.isAtEnd() {
+		switch p.advance().Type {
+		case token.LBRACE:
+			depth++
+		case token.RBRACE:
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+// tokenDescription describes a token for error messages.
+func tokenDescription(tok token.Token) string {
+	switch tok.Type {
+	case token.EOF:
+		return "end of file"
+	case token.TEMPLATE_MIDDLE, token.TEMPLATE_TAIL:
+		return "'}'" // the end of an embedded expression
+	}
+	return "'" + tok.Literal + "'"
+}
+
 // isAtEnd checks if the parser has reached the end of the token stream.
 func (p *Parser) isAtEnd() bool {
 	return p.current >= len(p.tokens) || p.peek().Type == token.EOF
@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/AlyxPink/meowlang/lexer"
+)
+
+func TestParserErrors(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedErrors []string
+	}{
+		{`lick = 5`, []string{"1:6: expected next token to be IDENT, got '=' instead"}},
+		{`lick x 5`, []string{"1:8: expected next token to be =, got '5' instead"}},
+		{`purr }`, []string{"1:6: unexpected token '}'"}},
+		{`purr 1 +`, []string{"1:9: unexpected token end of file"}},
+		{`meow add(a, 1) { claw a }`, []string{"1:13: expected next token to be IDENT, got '1' instead"}},
+		{`hiss (true) { purr 1`, []string{"1:21: expected next token to be }, got end of file instead"}},
+		{`purr 09`, []string{"1:6: could not parse 09 as integer"}},
+		{`purr [1, 2`, []string{"1:11: expected next token to be ], got end of file instead"}},
+		{`purr xs[]`, []string{"1:9: unexpected token ']'"}},
+		{`f() = 1`, []string{"1:5: cannot assign to this expression, only to a variable or an element"}},
+		{`purr {"a" 1}`, []string{"1:11: expected next token to be :, got '1' instead"}},
+		{`purr {"a": 1 "b": 2}`, []string{"1:14: expected ',' or '}' after a map entry, got 'b' instead"}},
+		{`purr {"a": 1`, []string{"1:13: expected ',' or '}' after a map entry, got end of file instead"}},
+		{`lick f = meow(x { claw x }`, []string{"1:17: expected next token to be ), got '{' instead"}},
+		{`shoo m`, []string{"1:1: shoo expects an element to remove, e.g. shoo m[\"key\"]"}},
+		{`purr 1e400`, []string{"1:6: could not parse 1e400 as float"}},
+		{
+			"lick = 1\npurr 2\n) purr 3\npurr +",
+			[]string{
+				"1:6: expected next token to be IDENT, got '=' instead",
+				"3:1: unexpected token ')'",
+				"4:6: unexpected token '+'",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.input)
+		p := NewParser(l.Tokenize())
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != len(tt.expectedErrors) {
+			t.Errorf("input %q: expected %d errors, got %d: %q", tt.input, len(tt.expectedErrors), len(errors), errors)
+			continue
+		}
+		for i, expected := range tt.expectedErrors {
+			if errors[i] != expected {
+				t.Errorf("input %q: errors[%d] expected %q, got %q", tt.input, i, expected, errors[i])
+			}
+		}
+	}
+}
+
+func TestParserRecoversAfterErrors(t *testing.T) {
+	input := `
+lick = 1
+purr 2
+hiss (true) { purr ) purr 3 }
+purr 4`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %q", len(p.Errors()), p.Errors())
+	}
+
+	// Statements with errors are left out of the program
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+	testPrintStatement(t, program.Statements[0], "2")
+	testPrintStatement(t, program.Statements[1], "4")
+}
+
+func TestExpressionStatements(t *testing.T) {
+	input := `add(1, 2); 5`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+	for i, expected := range []string{"add", "5"} {
+		if program.Statements[i].TokenLiteral() != expected {
+			t.Errorf("program.Statements[%d].TokenLiteral not %q. got=%q", i, expected, program.Statements[i].TokenLiteral())
+		}
+	}
+}
+
+func checkParserErrors(t *testing.T, p *Parser) {
+	errors := p.Errors()
+	if len(errors) == 0 {
+		return
+	}
+
+	t.Errorf("parser has %d errors", len(errors))
+	for _, msg := range errors {
+		t.Errorf("parser error: %q", msg)
+	}
+	t.FailNow()
+}
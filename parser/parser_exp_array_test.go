@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/lexer"
+)
+
+func TestParsingArrayLiterals(t *testing.T) {
+	input := `purr [1, 2 * 2, a]`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.PrintStatement)
+	array, ok := stmt.Value.(*ast.ArrayLiteral)
+	if !ok {
+		t.Fatalf("stmt.Value not *ast.ArrayLiteral. got=%T", stmt.Value)
+	}
+
+	if len(array.Elements) != 3 {
+		t.Fatalf("len(array.Elements) not 3. got=%d", len(array.Elements))
+	}
+
+	testIntegerLiteral(t, array.Elements[0], 1)
+	testInfixExpression(t, array.Elements[1], 2, "*", 2)
+	testIdentifier(t, array.Elements[2], "a")
+
+	if array.Pos().String() != "1:6" || array.End().String() != "1:19" {
+		t.Errorf("array positions wrong. expected=1:6-1:19, got=%s-%s", array.Pos(), array.End())
+	}
+}
+
+func TestParsingEmptyArrayLiteral(t *testing.T) {
+	l := lexer.NewLexer(`purr []`)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	array, ok := program.Statements[0].(*ast.PrintStatement).Value.(*ast.ArrayLiteral)
+	if !ok {
+		t.Fatalf("stmt.Value not *ast.ArrayLiteral. got=%T", program.Statements[0].(*ast.PrintStatement).Value)
+	}
+	if len(array.Elements) != 0 {
+		t.Errorf("len(array.Elements) not 0. got=%d", len(array.Elements))
+	}
+}
+
+func TestParsingIndexExpressions(t *testing.T) {
+	input := `purr xs[1 + 1]`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.PrintStatement)
+	index, ok := stmt.Value.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("stmt.Value not *ast.IndexExpression. got=%T", stmt.Value)
+	}
+
+	testIdentifier(t, index.Left, "xs")
+	testInfixExpression(t, index.Index, 1, "+", 1)
+
+	if index.Pos().String() != "1:6" || index.End().String() != "1:15" {
+		t.Errorf("index positions wrong. expected=1:6-1:15, got=%s-%s", index.Pos(), index.End())
+	}
+}
+
+func TestIndexPrecedence(t *testing.T) {
+	input := `purr -a * b[0][1]`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	// ((-a) * ((b[0])[1]))
+	product, ok := program.Statements[0].(*ast.PrintStatement).Value.(*ast.InfixExpression)
+	if !ok || product.Operator != "*" {
+		t.Fatalf("stmt.Value is not a '*' ast.InfixExpression. got=%T", program.Statements[0].(*ast.PrintStatement).Value)
+	}
+
+	outer, ok := product.Right.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("product.Right not *ast.IndexExpression. got=%T", product.Right)
+	}
+	testIntegerLiteral(t, outer.Index, 1)
+
+	inner, ok := outer.Left.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("outer.Left not *ast.IndexExpression. got=%T", outer.Left)
+	}
+	testIdentifier(t, inner.Left, "b")
+	testIntegerLiteral(t, inner.Index, 0)
+}
+
+func TestParsingIndexAssignStatements(t *testing.T) {
+	input := `xs[i - 1] = 2 * 3`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.IndexAssignStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.IndexAssignStatement. got=%T", program.Statements[0])
+	}
+
+	testIdentifier(t, stmt.Target.Left, "xs")
+	testInfixExpression(t, stmt.Target.Index, "i", "-", 1)
+	testInfixExpression(t, stmt.Value, 2, "*", 3)
+}
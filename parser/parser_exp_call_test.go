@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/lexer"
+)
+
+func TestParsingCallExpressions(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedArgs int
+	}{
+		{"purr add()", 0},
+		{"purr add(1)", 1},
+		{"purr add(1, 2 * 3, 4 + 5)", 3},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.input)
+		p := NewParser(l.Tokenize())
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%q: program.Statements does not contain 1 statement. got=%d", tt.input, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.PrintStatement)
+		if !ok {
+			t.Fatalf("%q: stmt not *ast.PrintStatement. got=%T", tt.input, program.Statements[0])
+		}
+
+		exp, ok := stmt.Value.(*ast.CallExpression)
+		if !ok {
+			t.Fatalf("%q: stmt.Value not *ast.CallExpression. got=%T", tt.input, stmt.Value)
+		}
+
+		testIdentifier(t, exp.Function, "add")
+
+		if len(exp.Arguments) != tt.expectedArgs {
+			t.Fatalf("%q: wrong number of arguments. want=%d, got=%d", tt.input, tt.expectedArgs, len(exp.Arguments))
+		}
+	}
+}
+
+func TestParsingCallExpressionArguments(t *testing.T) {
+	input := "purr add(1, 2 * 3, 4 + 5)"
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	exp := program.Statements[0].(*ast.PrintStatement).Value.(*ast.CallExpression)
+
+	testLiteralExpression(t, exp.Arguments[0], 1)
+	testInfixExpression(t, exp.Arguments[1], 2, "*", 3)
+	testInfixExpression(t, exp.Arguments[2], 4, "+", 5)
+}
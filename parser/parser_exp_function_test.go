@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/lexer"
+)
+
+func TestParsingFunctionLiterals(t *testing.T) {
+	input := `lick double = meow(x) { claw x * 2 }`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.AssignStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.AssignStatement. got=%T", program.Statements[0])
+	}
+
+	function, ok := stmt.Value.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Value not *ast.FunctionLiteral. got=%T", stmt.Value)
+	}
+
+	if len(function.Parameters) != 1 {
+		t.Fatalf("function.Parameters does not contain 1 parameter. got=%d", len(function.Parameters))
+	}
+	testLiteralExpression(t, function.Parameters[0], "x")
+
+	if len(function.Body.Statements) != 1 {
+		t.Fatalf("function.Body.Statements does not contain 1 statement. got=%d", len(function.Body.Statements))
+	}
+	returnStmt, ok := function.Body.Statements[0].(*ast.ReturnStatement)
+	if !ok {
+		t.Fatalf("function.Body.Statements[0] not *ast.ReturnStatement. got=%T", function.Body.Statements[0])
+	}
+	testInfixExpression(t, returnStmt.ReturnValue, "x", "*", 2)
+
+	if function.Pos().String() != "1:15" || function.End().String() != "1:37" {
+		t.Errorf("function positions wrong. expected=1:15-1:37, got=%s-%s", function.Pos(), function.End())
+	}
+}
+
+func TestParsingFunctionLiteralArguments(t *testing.T) {
+	input := `purr apply(meow(a, b) { claw a + b }, 1)`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	call := program.Statements[0].(*ast.PrintStatement).Value.(*ast.CallExpression)
+	if len(call.Arguments) != 2 {
+		t.Fatalf("call.Arguments does not contain 2 arguments. got=%d", len(call.Arguments))
+	}
+
+	function, ok := call.Arguments[0].(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("call.Arguments[0] not *ast.FunctionLiteral. got=%T", call.Arguments[0])
+	}
+	if len(function.Parameters) != 2 {
+		t.Fatalf("function.Parameters does not contain 2 parameters. got=%d", len(function.Parameters))
+	}
+	testIntegerLiteral(t, call.Arguments[1], 1)
+}
+
+func TestParsingImmediateInvocation(t *testing.T) {
+	input := `meow(x) { purr x }(5)`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	call, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression not *ast.CallExpression. got=%T", stmt.Expression)
+	}
+	if _, ok := call.Function.(*ast.FunctionLiteral); !ok {
+		t.Fatalf("call.Function not *ast.FunctionLiteral. got=%T", call.Function)
+	}
+	testIntegerLiteral(t, call.Arguments[0], 5)
+}
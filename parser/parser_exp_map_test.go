@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/lexer"
+)
+
+func TestParsingMapLiterals(t *testing.T) {
+	input := `purr {"name": "Mochi", "age": 1 + 2, true: a}`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.PrintStatement)
+	lit, ok := stmt.Value.(*ast.MapLiteral)
+	if !ok {
+		t.Fatalf("stmt.Value not *ast.MapLiteral. got=%T", stmt.Value)
+	}
+
+	if len(lit.Pairs) != 3 {
+		t.Fatalf("len(lit.Pairs) not 3. got=%d", len(lit.Pairs))
+	}
+
+	testStringLiteral(t, lit.Pairs[0].Key, "name")
+	testStringLiteral(t, lit.Pairs[0].Value, "Mochi")
+	testStringLiteral(t, lit.Pairs[1].Key, "age")
+	testInfixExpression(t, lit.Pairs[1].Value, 1, "+", 2)
+	testBooleanLiteral(t, lit.Pairs[2].Key, true)
+	testIdentifier(t, lit.Pairs[2].Value, "a")
+
+	if lit.Pos().String() != "1:6" || lit.End().String() != "1:46" {
+		t.Errorf("lit positions wrong. expected=1:6-1:46, got=%s-%s", lit.Pos(), lit.End())
+	}
+}
+
+func TestParsingEmptyMapLiteral(t *testing.T) {
+	l := lexer.NewLexer(`lick m = {}`)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.AssignStatement)
+	lit, ok := stmt.Value.(*ast.MapLiteral)
+	if !ok {
+		t.Fatalf("stmt.Value not *ast.MapLiteral. got=%T", stmt.Value)
+	}
+	if len(lit.Pairs) != 0 {
+		t.Errorf("len(lit.Pairs) not 0. got=%d", len(lit.Pairs))
+	}
+}
+
+func TestParsingDeleteStatements(t *testing.T) {
+	l := lexer.NewLexer(`shoo m["name"]; purr m`)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.DeleteStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.DeleteStatement. got=%T", program.Statements[0])
+	}
+
+	testIdentifier(t, stmt.Target.Left, "m")
+	testStringLiteral(t, stmt.Target.Index, "name")
+}
@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/lexer"
+)
+
+func TestParsingInterpolatedStrings(t *testing.T) {
+	input := `purr "Result of {a} + {b}: {a + b}"`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.PrintStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.PrintStatement. got=%T", program.Statements[0])
+	}
+
+	str, ok := stmt.Value.(*ast.InterpolatedString)
+	if !ok {
+		t.Fatalf("stmt.Value not *ast.InterpolatedString. got=%T", stmt.Value)
+	}
+
+	if len(str.Parts) != 6 {
+		t.Fatalf("str.Parts does not contain 6 parts. got=%d", len(str.Parts))
+	}
+
+	testStringLiteral(t, str.Parts[0], "Result of ")
+	testIdentifier(t, str.Parts[1], "a")
+	testStringLiteral(t, str.Parts[2], " + ")
+	testIdentifier(t, str.Parts[3], "b")
+	testStringLiteral(t, str.Parts[4], ": ")
+	testInfixExpression(t, str.Parts[5], "a", "+", "b")
+
+	if str.Pos().String() != "1:6" || str.End().String() != "1:36" {
+		t.Errorf("str positions wrong. expected=1:6-1:36, got=%s-%s", str.Pos(), str.End())
+	}
+}
+
+func TestParsingNestedInterpolatedStrings(t *testing.T) {
+	input := `purr "outer {"inner {x}"}"`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	outer := program.Statements[0].(*ast.PrintStatement).Value.(*ast.InterpolatedString)
+	if len(outer.Parts) != 2 {
+		t.Fatalf("outer.Parts does not contain 2 parts. got=%d", len(outer.Parts))
+	}
+	testStringLiteral(t, outer.Parts[0], "outer ")
+
+	inner, ok := outer.Parts[1].(*ast.InterpolatedString)
+	if !ok {
+		t.Fatalf("outer.Parts[1] not *ast.InterpolatedString. got=%T", outer.Parts[1])
+	}
+	if len(inner.Parts) != 2 {
+		t.Fatalf("inner.Parts does not contain 2 parts. got=%d", len(inner.Parts))
+	}
+	testStringLiteral(t, inner.Parts[0], "inner ")
+	testIdentifier(t, inner.Parts[1], "x")
+}
+
+func TestInterpolatedStringErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{`purr "two {a b}"`, "1:14: expected '}' to end the embedded expression, got 'b' instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.input)
+		p := NewParser(l.Tokenize())
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expectedError {
+			t.Errorf("input %q: expected error %q, got %q", tt.input, tt.expectedError, p.Errors())
+		}
+	}
+}
+
+func testStringLiteral(t *testing.T, exp ast.Expression, value string) bool {
+	str, ok := exp.(*ast.StringLiteral)
+	if !ok {
+		t.Errorf("exp not *ast.StringLiteral. got=%T", exp)
+		return false
+	}
+
+	if str.Value != value {
+		t.Errorf("str.Value not %q. got=%q", value, str.Value)
+		return false
+	}
+
+	return true
+}
@@ -5,14 +5,177 @@ import (
 	"testing"
 
 	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/lexer"
 )
 
+func TestParsingInfixExpressions(t *testing.T) {
+	tests := []struct {
+		input      string
+		leftValue  interface{}
+		operator   string
+		rightValue interface{}
+	}{
+		{"purr 5 + 5", 5, "+", 5},
+		{"purr 5 - 5", 5, "-", 5},
+		{"purr 5 * 5", 5, "*", 5},
+		{"purr 5 / 5", 5, "/", 5},
+		{"purr 5 > 5", 5, ">", 5},
+		{"purr 5 < 5", 5, "<", 5},
+		{"purr 5 >= 5", 5, ">=", 5},
+		{"purr 5 <= 5", 5, "<=", 5},
+		{"purr 5 == 5", 5, "==", 5},
+		{"purr 5 != 5", 5, "!=", 5},
+		{"purr a == b", "a", "==", "b"},
+		{"purr true == true", true, "==", true},
+		{"purr true != false", true, "!=", false},
+		{"purr 1.5 * 2", 1.5, "*", 2},
+		{"purr 7 / 2.0", 7, "/", 2.0},
+		{"purr 2.5e-3 < 1e3", 0.0025, "<", 1000.0},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.input)
+		p := NewParser(l.Tokenize())
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%q: program.Statements does not contain 1 statement. got=%d", tt.input, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.PrintStatement)
+		if !ok {
+			t.Fatalf("%q: stmt not *ast.PrintStatement. got=%T", tt.input, program.Statements[0])
+		}
+
+		testInfixExpression(t, stmt.Value, tt.leftValue, tt.operator, tt.rightValue)
+	}
+}
+
+func TestParsingBigIntegerLiterals(t *testing.T) {
+	input := `purr 99999999999999999999`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.PrintStatement)
+	lit, ok := stmt.Value.(*ast.IntegerLiteral)
+	if !ok {
+		t.Fatalf("stmt.Value not *ast.IntegerLiteral. got=%T", stmt.Value)
+	}
+
+	if lit.Big == nil || lit.Big.String() != "99999999999999999999" {
+		t.Errorf("lit.Big not 99999999999999999999. got=%v", lit.Big)
+	}
+}
+
+func TestParsingPrefixExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		operator string
+		value    interface{}
+	}{
+		{"purr !5", "!", 5},
+		{"purr -15", "-", 15},
+		{"purr !foobar", "!", "foobar"},
+		{"purr -foobar", "-", "foobar"},
+		{"purr !true", "!", true},
+		{"purr !false", "!", false},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.input)
+		p := NewParser(l.Tokenize())
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%q: program.Statements does not contain 1 statement. got=%d", tt.input, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.PrintStatement)
+		if !ok {
+			t.Fatalf("%q: stmt not *ast.PrintStatement. got=%T", tt.input, program.Statements[0])
+		}
+
+		exp, ok := stmt.Value.(*ast.PrefixExpression)
+		if !ok {
+			t.Fatalf("%q: stmt.Value not *ast.PrefixExpression. got=%T", tt.input, stmt.Value)
+		}
+		if exp.Operator != tt.operator {
+			t.Fatalf("%q: exp.Operator is not '%s'. got=%s", tt.input, tt.operator, exp.Operator)
+		}
+		testLiteralExpression(t, exp.Right, tt.value)
+	}
+}
+
+func TestPrefixPrecedence(t *testing.T) {
+	input := `purr -a * b`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.PrintStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.PrintStatement. got=%T", program.Statements[0])
+	}
+
+	// ((-a) * b)
+	product, ok := stmt.Value.(*ast.InfixExpression)
+	if !ok || product.Operator != "*" {
+		t.Fatalf("stmt.Value is not a '*' ast.InfixExpression. got=%T(%+v)", stmt.Value, stmt.Value)
+	}
+	testIdentifier(t, product.Right, "b")
+
+	negation, ok := product.Left.(*ast.PrefixExpression)
+	if !ok || negation.Operator != "-" {
+		t.Fatalf("product.Left is not a '-' ast.PrefixExpression. got=%T(%+v)", product.Left, product.Left)
+	}
+	testIdentifier(t, negation.Right, "a")
+}
+
+func TestComparisonPrecedence(t *testing.T) {
+	input := `purr a + 1 < b == c`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.PrintStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.PrintStatement. got=%T", program.Statements[0])
+	}
+
+	// ((a + 1) < b) == c
+	equality, ok := stmt.Value.(*ast.InfixExpression)
+	if !ok || equality.Operator != "==" {
+		t.Fatalf("stmt.Value is not an '==' ast.InfixExpression. got=%T(%+v)", stmt.Value, stmt.Value)
+	}
+	testIdentifier(t, equality.Right, "c")
+
+	comparison, ok := equality.Left.(*ast.InfixExpression)
+	if !ok || comparison.Operator != "<" {
+		t.Fatalf("equality.Left is not a '<' ast.InfixExpression. got=%T(%+v)", equality.Left, equality.Left)
+	}
+	testIdentifier(t, comparison.Right, "b")
+	testInfixExpression(t, comparison.Left, "a", "+", 1)
+}
+
 func testLiteralExpression(t *testing.T, exp ast.Expression, expected interface{}) bool {
 	switch v := expected.(type) {
 	case int:
 		return testIntegerLiteral(t, exp, int64(v))
+	case float64:
+		return testFloatLiteral(t, exp, v)
 	case string:
 		return testIdentifier(t, exp, v)
+	case bool:
+		return testBooleanLiteral(t, exp, v)
 	}
 	t.Errorf("type of exp not handled. got=%T", exp)
 	return false
@@ -38,6 +201,41 @@ func testIntegerLiteral(t *testing.T, il ast.Expression, value int64) bool {
 	return true
 }
 
+func testFloatLiteral(t *testing.T, exp ast.Expression, value float64) bool {
+	fl, ok := exp.(*ast.FloatLiteral)
+	if !ok {
+		t.Errorf("exp not *ast.FloatLiteral. got=%T", exp)
+		return false
+	}
+
+	if fl.Value != value {
+		t.Errorf("fl.Value not %g. got=%g", value, fl.Value)
+		return false
+	}
+
+	return true
+}
+
+func testBooleanLiteral(t *testing.T, exp ast.Expression, value bool) bool {
+	bo, ok := exp.(*ast.BooleanLiteral)
+	if !ok {
+		t.Errorf("exp not *ast.BooleanLiteral. got=%T", exp)
+		return false
+	}
+
+	if bo.Value != value {
+		t.Errorf("bo.Value not %t. got=%t", value, bo.Value)
+		return false
+	}
+
+	if bo.TokenLiteral() != fmt.Sprintf("%t", value) {
+		t.Errorf("bo.TokenLiteral not %t. got=%s", value, bo.TokenLiteral())
+		return false
+	}
+
+	return true
+}
+
 func testIdentifier(t *testing.T, exp ast.Expression, value string) bool {
 	ident, ok := exp.(*ast.Identifier)
 	if !ok {
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/lexer"
+)
+
+func TestNodePositions(t *testing.T) {
+	input := `lick total = add(1, 2) * -x
+hiss (total > 3) {
+    purr "big"
+} growl hiss (total < 0) {
+    claw
+}
+scratch (false) { nap(1) }`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	assign := program.Statements[0].(*ast.AssignStatement)
+	product := assign.Value.(*ast.InfixExpression)
+	call := product.Left.(*ast.CallExpression)
+	negation := product.Right.(*ast.PrefixExpression)
+	ifStmt := program.Statements[1].(*ast.IfStatement)
+	nested := ifStmt.Alternative.Statements[0].(*ast.IfStatement)
+	whileStmt := program.Statements[2].(*ast.WhileStatement)
+
+	tests := []struct {
+		name        string
+		node        ast.Node
+		expectedPos string
+		expectedEnd string
+	}{
+		{"program", program, "1:1", "7:27"},
+		{"assign", assign, "1:1", "1:28"},
+		{"product", product, "1:14", "1:28"},
+		{"call", call, "1:14", "1:23"},
+		{"call argument", call.Arguments[1], "1:21", "1:22"},
+		{"negation", negation, "1:26", "1:28"},
+		{"if", ifStmt, "2:1", "6:2"},
+		{"condition", ifStmt.Condition, "2:7", "2:16"},
+		{"consequence", ifStmt.Consequence, "2:18", "4:2"},
+		{"print", ifStmt.Consequence.Statements[0], "3:5", "3:15"},
+		{"growl hiss", ifStmt.Alternative, "4:9", "6:2"},
+		{"nested if", nested, "4:9", "6:2"},
+		{"bare claw", nested.Consequence.Statements[0], "5:5", "5:9"},
+		{"while", whileStmt, "7:1", "7:27"},
+		// parentheses are not part of the AST, so the nap ends with its duration
+		{"nap", whileStmt.Body.Statements[0], "7:19", "7:24"},
+	}
+
+	for _, tt := range tests {
+		if pos := tt.node.Pos().String(); pos != tt.expectedPos {
+			t.Errorf("%s: Pos() wrong. expected=%s, got=%s", tt.name, tt.expectedPos, pos)
+		}
+		if end := tt.node.End().String(); end != tt.expectedEnd {
+			t.Errorf("%s: End() wrong. expected=%s, got=%s", tt.name, tt.expectedEnd, end)
+		}
+	}
+}
@@ -16,6 +16,7 @@ func TestParsingAssignStatements(t *testing.T) {
 	p := NewParser(l.Tokenize())
 
 	program := p.ParseProgram()
+	checkParserErrors(t, p)
 	if program == nil {
 		t.Fatalf("ParseProgram() returned nil")
 	}
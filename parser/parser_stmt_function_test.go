@@ -18,6 +18,7 @@ func TestParsingFunctionStatements(t *testing.T) {
 	p := NewParser(l.Tokenize())
 
 	program := p.ParseProgram()
+	checkParserErrors(t, p)
 	if program == nil {
 		t.Fatalf("ParseProgram() returned nil")
 	}
@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/lexer"
+)
+
+func TestParsingIfStatements(t *testing.T) {
+	input := `
+	hiss (a < b) {
+		purr "a is less than b"
+	} growl {
+		purr "a is not less than b"
+	}`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if program == nil {
+		t.Fatalf("ParseProgram() returned nil")
+	}
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	ifStmt, ok := program.Statements[0].(*ast.IfStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.IfStatement. got=%T", program.Statements[0])
+	}
+
+	testInfixExpression(t, ifStmt.Condition, "a", "<", "b")
+
+	if len(ifStmt.Consequence.Statements) != 1 {
+		t.Fatalf("ifStmt.Consequence.Statements does not contain 1 statement. got=%d", len(ifStmt.Consequence.Statements))
+	}
+	testPrintStatement(t, ifStmt.Consequence.Statements[0], "a is less than b")
+
+	if ifStmt.Alternative == nil {
+		t.Fatalf("ifStmt.Alternative is nil")
+	}
+	if len(ifStmt.Alternative.Statements) != 1 {
+		t.Fatalf("ifStmt.Alternative.Statements does not contain 1 statement. got=%d", len(ifStmt.Alternative.Statements))
+	}
+	testPrintStatement(t, ifStmt.Alternative.Statements[0], "a is not less than b")
+}
+
+func TestParsingIfStatementWithoutAlternative(t *testing.T) {
+	input := `hiss (a > b) { purr a }`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	ifStmt, ok := program.Statements[0].(*ast.IfStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.IfStatement. got=%T", program.Statements[0])
+	}
+
+	testInfixExpression(t, ifStmt.Condition, "a", ">", "b")
+
+	if ifStmt.Alternative != nil {
+		t.Fatalf("ifStmt.Alternative is not nil. got=%+v", ifStmt.Alternative)
+	}
+}
+
+func TestParsingIfStatementChains(t *testing.T) {
+	input := `
+	hiss (a < b) {
+		purr "less"
+	} growl hiss (a > b) {
+		purr "greater"
+	} growl {
+		purr "equal"
+	}`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	ifStmt, ok := program.Statements[0].(*ast.IfStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.IfStatement. got=%T", program.Statements[0])
+	}
+
+	if ifStmt.Alternative == nil || len(ifStmt.Alternative.Statements) != 1 {
+		t.Fatalf("ifStmt.Alternative does not contain the nested conditional. got=%+v", ifStmt.Alternative)
+	}
+
+	nested, ok := ifStmt.Alternative.Statements[0].(*ast.IfStatement)
+	if !ok {
+		t.Fatalf("ifStmt.Alternative.Statements[0] not *ast.IfStatement. got=%T", ifStmt.Alternative.Statements[0])
+	}
+
+	testInfixExpression(t, nested.Condition, "a", ">", "b")
+	testPrintStatement(t, nested.Consequence.Statements[0], "greater")
+
+	if nested.Alternative == nil || len(nested.Alternative.Statements) != 1 {
+		t.Fatalf("nested.Alternative does not contain 1 statement. got=%+v", nested.Alternative)
+	}
+	testPrintStatement(t, nested.Alternative.Statements[0], "equal")
+}
@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/lexer"
+)
+
+func TestParsingNapStatements(t *testing.T) {
+	input := `
+nap(1)
+nap 2;
+nap(a * 3)
+`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if program == nil {
+		t.Fatalf("ParseProgram() returned nil")
+	}
+
+	if len(program.Statements) != 3 {
+		t.Fatalf("program.Statements does not contain 3 statements. got=%d", len(program.Statements))
+	}
+
+	for i, stmt := range program.Statements {
+		if stmt.TokenLiteral() != "nap" {
+			t.Fatalf("program.Statements[%d].TokenLiteral not 'nap'. got=%q", i, stmt.TokenLiteral())
+		}
+		if _, ok := stmt.(*ast.NapStatement); !ok {
+			t.Fatalf("program.Statements[%d] not *ast.NapStatement. got=%T", i, stmt)
+		}
+	}
+
+	testIntegerLiteral(t, program.Statements[0].(*ast.NapStatement).Duration, 1)
+	testIntegerLiteral(t, program.Statements[1].(*ast.NapStatement).Duration, 2)
+	testInfixExpression(t, program.Statements[2].(*ast.NapStatement).Duration, "a", "*", 3)
+}
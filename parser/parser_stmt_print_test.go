@@ -17,6 +17,7 @@ purr 5
 	p := NewParser(l.Tokenize())
 
 	program := p.ParseProgram()
+	checkParserErrors(t, p)
 	if program == nil {
 		t.Fatalf("ParseProgram() returned nil")
 	}
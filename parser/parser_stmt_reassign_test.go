@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/lexer"
+)
+
+func TestParsingReassignStatements(t *testing.T) {
+	input := `x = 5;
+              y = x
+              foobar = x + y;`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if program == nil {
+		t.Fatalf("ParseProgram() returned nil")
+	}
+
+	if len(program.Statements) != 3 {
+		t.Fatalf("program.Statements does not contain 3 statements. got=%d", len(program.Statements))
+	}
+
+	tests := []struct {
+		expectedIdentifier string
+	}{
+		{"x"},
+		{"y"},
+		{"foobar"},
+	}
+
+	for i, tt := range tests {
+		stmt := program.Statements[i]
+		if !testReassignStatement(t, stmt, tt.expectedIdentifier) {
+			return
+		}
+	}
+}
+
+func testReassignStatement(t *testing.T, s ast.Statement, name string) bool {
+	if s == nil {
+		t.Errorf("Reassign statement is nil")
+		return false
+	}
+
+	if s.TokenLiteral() != "=" {
+		t.Errorf("s.TokenLiteral not '='. got=%q", s.TokenLiteral())
+		return false
+	}
+
+	reassignStmt, ok := s.(*ast.ReassignStatement)
+	if !ok {
+		t.Errorf("s not *ast.ReassignStatement. got=%T", s)
+		return false
+	}
+
+	if reassignStmt.Name.Value != name {
+		t.Errorf("reassignStmt.Name.Value not '%s'. got=%s", name, reassignStmt.Name.Value)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/lexer"
+)
+
+func TestParsingWhileStatements(t *testing.T) {
+	input := `
+	scratch (a < b) {
+		purr a
+		a = a + 1
+	}`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l.Tokenize())
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if program == nil {
+		t.Fatalf("ParseProgram() returned nil")
+	}
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	whileStmt, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.WhileStatement. got=%T", program.Statements[0])
+	}
+
+	testInfixExpression(t, whileStmt.Condition, "a", "<", "b")
+
+	if len(whileStmt.Body.Statements) != 2 {
+		t.Fatalf("whileStmt.Body.Statements does not contain 2 statements. got=%d", len(whileStmt.Body.Statements))
+	}
+
+	testPrintStatement(t, whileStmt.Body.Statements[0], "a")
+
+	reassignStmt, ok := whileStmt.Body.Statements[1].(*ast.ReassignStatement)
+	if !ok {
+		t.Fatalf("whileStmt.Body.Statements[1] not *ast.ReassignStatement. got=%T", whileStmt.Body.Statements[1])
+	}
+	testReassignStatement(t, reassignStmt, "a")
+	testInfixExpression(t, reassignStmt.Value, "a", "+", 1)
+}
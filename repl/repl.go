@@ -0,0 +1,234 @@
+// Package repl implements the interactive MeowLang shell, run by meowlang without arguments.
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/interpreter"
+	"github.com/AlyxPink/meowlang/lexer"
+	"github.com/AlyxPink/meowlang/meow"
+	"github.com/AlyxPink/meowlang/object"
+	"github.com/AlyxPink/meowlang/parser"
+	"github.com/AlyxPink/meowlang/token"
+)
+
+const (
+	PROMPT       = "🐱> "
+	CONTINUATION = "... "
+)
+
+const help = `Type MeowLang code to run it. Expressions print their value.
+Meta-commands:
+  :env          list the variables defined so far
+  :reset        forget every variable
+  :load <file>  run a file, keeping its variables
+  :tokens <src> show the tokens of some code
+  :history      list the previous inputs
+  :help         show this help
+  :quit         exit, like Ctrl+D
+`
+
+// REPL reads code from an input, line by line, and runs it in an environment
+// that is kept from one input to the next.
+type REPL struct {
+	in      *bufio.Reader
+	out     io.Writer
+	errOut  io.Writer // where errors are written
+	backend meow.Backend
+	engine  interpreter.Engine
+	history []string
+}
+
+// New creates a REPL reading from in and writing to out, errors included. The
+// 'input' built-in function reads from in too.
+func New(in io.Reader, out io.Writer) *REPL {
+	return NewWithWriters(in, out, out)
+}
+
+// NewWithWriters creates a REPL reading from in, writing to stdout and writing
+// errors to stderr. The 'input' built-in function reads from in too.
+func NewWithWriters(in io.Reader, stdout, stderr io.Writer) *REPL {
+	r := &REPL{in: bufio.NewReader(in), out: stdout, errOut: stderr}
+	// The default backend is always valid
+	_ = r.reset()
+	return r
+}
+
+// Start runs a REPL until its input ends.
+func Start(in io.Reader, out io.Writer) {
+	New(in, out).Run()
+}
+
+// SetBackend sets how the inputs run, the interpreter by default. It starts
+// over with an empty environment.
+func (r *REPL) SetBackend(backend meow.Backend) error {
+	if err := backend.Validate(); err != nil {
+		return err
+	}
+	r.backend = backend
+	return r.reset()
+}
+
+// Run reads and runs inputs until the input ends or :quit.
+func (r *REPL) Run() {
+	for {
+		src, ok := r.readInput()
+		if !ok {
+			fmt.Fprintln(r.out)
+			return
+		}
+
+		src = strings.TrimSpace(src)
+		if src == "" {
+			continue
+		}
+		r.history = append(r.history, src)
+
+		if strings.HasPrefix(src, ":") {
+			if quit := r.runCommand(src); quit {
+				return
+			}
+			continue
+		}
+
+		r.eval(src, "")
+	}
+}
+
+// readInput reads an input, which continues over several lines while it has
+// unclosed brackets, e.g. the '{' of a function body. An empty line ends it anyway.
+func (r *REPL) readInput() (string, bool) {
+	var src strings.Builder
+
+	fmt.Fprint(r.out, PROMPT)
+	for {
+		line, err := r.in.ReadString('\n')
+		if err != nil && line == "" {
+			return src.String(), src.Len() > 0
+		}
+		src.WriteString(line)
+
+		if strings.TrimSpace(line) == "" || !isIncomplete(src.String()) || err != nil {
+			return src.String(), true
+		}
+		fmt.Fprint(r.out, CONTINUATION)
+	}
+}
+
+// isIncomplete reports whether some code has more opening than closing brackets,
+// meaning that it continues on the next line.
+func isIncomplete(src string) bool {
+	depth := 0
+	for _, tok := range lexer.NewLexer(src).Tokenize() {
+		switch tok.Type {
+		case token.LBRACE, token.LPAREN, token.LBRACKET:
+			depth++
+		case token.RBRACE, token.RPAREN, token.RBRACKET:
+			depth--
+		}
+	}
+	return depth > 0
+}
+
+// runCommand runs a meta-command, e.g. ":env". It reports whether the REPL must stop.
+func (r *REPL) runCommand(src string) bool {
+	command, arg, _ := strings.Cut(src, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch command {
+	case ":env":
+		for _, name := range r.engine.Env().Names() {
+			value, _ := r.engine.Env().Get(name)
+			fmt.Fprintf(r.out, "%s = %s\n", name, value.Inspect())
+		}
+	case ":reset":
+		if err := r.reset(); err != nil {
+			fmt.Fprintf(r.errOut, "error: %s\n", err)
+			break
+		}
+		fmt.Fprintln(r.out, "Environment reset.")
+	case ":load":
+		if arg == "" {
+			fmt.Fprintln(r.out, "usage: :load <file>")
+			break
+		}
+		content, err := os.ReadFile(arg)
+		if err != nil {
+			fmt.Fprintf(r.errOut, "error: %s\n", err)
+			break
+		}
+		r.eval(string(content), arg)
+	case ":tokens":
+		l := lexer.NewLexer(arg)
+		for _, tok := range l.Tokenize() {
+			fmt.Fprintf(r.out, "%s\t%s\t%q\n", tok.Pos, tok.Type, tok.Literal)
+		}
+		for _, msg := range l.Errors() {
+			fmt.Fprintf(r.errOut, "error: %s\n", msg)
+		}
+	case ":history":
+		for i, entry := range r.history {
+			fmt.Fprintf(r.out, "%3d  %s\n", i+1, strings.ReplaceAll(entry, "\n", "\n     "))
+		}
+	case ":help":
+		fmt.Fprint(r.out, help)
+	case ":quit", ":exit":
+		return true
+	default:
+		fmt.Fprintf(r.out, "unknown command %s, type :help for the list of commands\n", command)
+	}
+	return false
+}
+
+// reset starts over with an empty environment. The environment is kept if
+// no engine can be created for the backend.
+func (r *REPL) reset() error {
+	engine, err := meow.NewEngine(r.backend, r.out, r.errOut)
+	if err != nil {
+		return err
+	}
+	r.engine = engine
+	r.engine.SetInput(r.in)
+	return nil
+}
+
+// eval runs some code in the environment of the REPL. The value of a final
+// expression statement is printed, unless it is null. Errors are prefixed by
+// name, e.g. the name of a loaded file, see meow.Diagnostic.
+func (r *REPL) eval(src string, name string) {
+	l := lexer.NewLexer(src)
+	p := parser.NewParser(l.Tokenize())
+	program := p.ParseProgram()
+
+	if errors := append(l.Errors(), p.Errors()...); len(errors) > 0 {
+		for _, msg := range errors {
+			if name != "" {
+				msg = name + ":" + msg // syntax errors are always located
+			}
+			fmt.Fprintf(r.errOut, "error: %s\n", msg)
+		}
+		return
+	}
+
+	result := r.engine.Interpret(program)
+
+	if err, ok := result.(*object.Error); ok {
+		fmt.Fprintf(r.errOut, "error: %s\n", meow.Diagnostic(name, err))
+		return
+	}
+
+	if len(program.Statements) == 0 {
+		return
+	}
+	if _, ok := program.Statements[len(program.Statements)-1].(*ast.ExpressionStatement); !ok {
+		return
+	}
+	if result != nil && result.Type() != object.NULL_OBJ {
+		fmt.Fprintln(r.out, result.Inspect())
+	}
+}
@@ -0,0 +1,157 @@
+package repl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AlyxPink/meowlang/meow"
+)
+
+// session runs a REPL over some input and returns its output, without the prompts.
+func session(input string) string {
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	output := strings.ReplaceAll(out.String(), PROMPT, "")
+	return strings.ReplaceAll(output, CONTINUATION, "")
+}
+
+func TestREPL_PersistentEnvironment(t *testing.T) {
+	input := "lick x = 2\nx = x + 1\npurr x\nx * 10\n"
+	expected := "3\n30\n\n"
+
+	if output := session(input); output != expected {
+		t.Errorf("expected output %q, got %q", expected, output)
+	}
+}
+
+func TestREPL_EchoesExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 + 2\n", "3\n\n"},
+		{"\"meow\"\n", "meow\n\n"},
+		{"[1, \"a\"]\n", "[1, \"a\"]\n\n"},
+		{"lick x = 5\n", "\n"},
+		{"meow f() { claw }\nf()\n", "\n"},
+		{"meow f() { claw 1 }\n", "\n"},
+	}
+
+	for _, tt := range tests {
+		if output := session(tt.input); output != tt.expected {
+			t.Errorf("input %q: expected output %q, got %q", tt.input, tt.expected, output)
+		}
+	}
+}
+
+func TestREPL_Stderr(t *testing.T) {
+	input := "purr \"meow\"\nmissing\nlick = 1\n:load missing.meow\n1 + 1\n"
+	expectedOut := "meow\n2\n\n"
+	expectedErr := "error: 1:1: identifier not found: missing\n" +
+		"error: 1:6: expected next token to be IDENT, got '=' instead\n" +
+		"error: open missing.meow: no such file or directory\n"
+
+	var out, stderr bytes.Buffer
+	NewWithWriters(strings.NewReader(input), &out, &stderr).Run()
+
+	if output := strings.ReplaceAll(out.String(), PROMPT, ""); output != expectedOut {
+		t.Errorf("expected output %q, got %q", expectedOut, output)
+	}
+	if stderr.String() != expectedErr {
+		t.Errorf("expected errors %q, got %q", expectedErr, stderr.String())
+	}
+}
+
+func TestREPL_Backend(t *testing.T) {
+	input := "lick x = 2\nmeow f(n) { x = x + n }\nf(3)\nmissing\n:reset\nx\n"
+	expected := "5\nerror: 1:1: identifier not found: missing\nEnvironment reset.\nerror: 1:1: identifier not found: x\n\n"
+
+	var out bytes.Buffer
+	r := New(strings.NewReader(input), &out)
+	if err := r.SetBackend(meow.VM); err != nil {
+		t.Fatal(err)
+	}
+	r.Run()
+
+	output := strings.ReplaceAll(out.String(), PROMPT, "")
+	if output != expected {
+		t.Errorf("expected output %q, got %q", expected, output)
+	}
+	if err := r.SetBackend("jit"); err == nil {
+		t.Errorf("expected an error for an unknown backend")
+	}
+}
+
+func TestREPL_IncompleteInput(t *testing.T) {
+	input := "meow add(a, b) {\n  claw a + b\n}\nadd(1,\n 2)\nhiss (true) {\n\npurr \"after\"\n"
+	expected := "3\nerror: 1:14: expected next token to be }, got end of file instead\nafter\n\n"
+
+	if output := session(input); output != expected {
+		t.Errorf("expected output %q, got %q", expected, output)
+	}
+}
+
+func TestREPL_Errors(t *testing.T) {
+	input := "purr missing\nlick = 1\nlick ok = 1\nok\n"
+	expected := "error: 1:6: identifier not found: missing\n" +
+		"error: 1:6: expected next token to be IDENT, got '=' instead\n" +
+		"1\n\n"
+
+	if output := session(input); output != expected {
+		t.Errorf("expected output %q, got %q", expected, output)
+	}
+}
+
+func TestREPL_Commands(t *testing.T) {
+	input := ":env\nlick b = 2\nlick a = [1]\n:env\n:reset\n:env\nb\n:tokens purr 1\n:nope\n:quit\npurr \"unreachable\"\n"
+	expected := "a = [1]\nb = 2\n" +
+		"Environment reset.\n" +
+		"error: 1:1: identifier not found: b\n" +
+		"1:1\tPURR\t\"purr\"\n1:6\tINT\t\"1\"\n1:7\tEOF\t\"\"\n" +
+		"unknown command :nope, type :help for the list of commands\n"
+
+	if output := session(input); output != expected {
+		t.Errorf("expected output %q, got %q", expected, output)
+	}
+}
+
+func TestREPL_History(t *testing.T) {
+	input := "lick x = 1\nmeow f() {\nclaw x\n}\n:history\n"
+	expected := "  1  lick x = 1\n  2  meow f() {\n     claw x\n     }\n  3  :history\n\n"
+
+	if output := session(input); output != expected {
+		t.Errorf("expected output %q, got %q", expected, output)
+	}
+}
+
+func TestREPL_Load(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.meow")
+	bad := filepath.Join(dir, "bad.meow")
+	if err := os.WriteFile(good, []byte("lick name = \"Mochi\"\npurr \"loaded\""), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bad, []byte("purr 1 / 0"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	input := ":load " + good + "\nname\n:load " + bad + "\n:load\n"
+	expected := "loaded\nMochi\nerror: " + bad + ":1:8: division by zero\nusage: :load <file>\n\n"
+
+	if output := session(input); output != expected {
+		t.Errorf("expected output %q, got %q", expected, output)
+	}
+}
+
+func TestREPL_Input(t *testing.T) {
+	input := "lick name = input()\nMochi\nname\n"
+	expected := "Mochi\n\n"
+
+	if output := session(input); output != expected {
+		t.Errorf("expected output %q, got %q", expected, output)
+	}
+}
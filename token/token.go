@@ -1,5 +1,7 @@
 package token
 
+import "fmt"
+
 type TokenType string
 
 const (
@@ -10,8 +12,15 @@ const (
 	// Identifiers + literals
 	IDENT  TokenType = "IDENT"
 	INT    TokenType = "INT"
+	FLOAT  TokenType = "FLOAT"
 	STRING TokenType = "STRING"
 
+	// Interpolated strings, e.g. "a {x} b {y} c" is lexed as
+	// TEMPLATE_HEAD("a "), x, TEMPLATE_MIDDLE(" b "), y, TEMPLATE_TAIL(" c")
+	TEMPLATE_HEAD   TokenType = "TEMPLATE_HEAD"
+	TEMPLATE_MIDDLE TokenType = "TEMPLATE_MIDDLE"
+	TEMPLATE_TAIL   TokenType = "TEMPLATE_TAIL"
+
 	// Operators
 	ASSIGN   = "="
 	PLUS     = "+"
@@ -20,8 +29,10 @@ const (
 	ASTERISK = "*"
 	SLASH    = "/"
 
-	LT = "<"
-	GT = ">"
+	LT    = "<"
+	GT    = ">"
+	LT_EQ = "<="
+	GT_EQ = ">="
 
 	EQ     = "=="
 	NOT_EQ = "!="
@@ -29,6 +40,7 @@ const (
 	// Delimiters
 	COMMA     = ","
 	SEMICOLON = ";"
+	COLON     = ":"
 
 	LPAREN = "("
 	RPAREN = ")"
@@ -36,8 +48,12 @@ const (
 	LBRACE = "{"
 	RBRACE = "}"
 
+	LBRACKET = "["
+	RBRACKET = "]"
+
 	// Keywords
 	CLAW    = "CLAW"
+	FALSE   = "FALSE"
 	GROWL   = "GROWL"
 	HISS    = "HISS"
 	LICK    = "LICK"
@@ -45,15 +61,40 @@ const (
 	NAP     = "NAP"
 	PURR    = "PURR"
 	SCRATCH = "SCRATCH"
+	SHOO    = "SHOO"
+	TRUE    = "TRUE"
 )
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position // position of the first character of the token
+	End     Position // position just past the last character of the token
+}
+
+// Position is a location in the source code.
+type Position struct {
+	Offset int // byte offset, starting at 0
+	Line   int // line number, starting at 1
+	Column int // column number, starting at 1
+}
+
+// IsValid reports whether the position was set.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position as "line:column", or "-" if it is not valid.
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
 }
 
 var keywords = map[string]TokenType{
 	"claw":    CLAW,
+	"false":   FALSE,
 	"growl":   GROWL,
 	"hiss":    HISS,
 	"lick":    LICK,
@@ -61,6 +102,8 @@ var keywords = map[string]TokenType{
 	"nap":     NAP,
 	"purr":    PURR,
 	"scratch": SCRATCH,
+	"shoo":    SHOO,
+	"true":    TRUE,
 }
 
 func LookupIdent(ident string) TokenType {
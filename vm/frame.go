@@ -0,0 +1,68 @@
+package vm
+
+import (
+	"github.com/AlyxPink/meowlang/compiler"
+	"github.com/AlyxPink/meowlang/object"
+)
+
+// Closure is a compiled function with the environment it was defined in, so
+// its body can use the variables in scope there, even after that scope ends.
+type Closure struct {
+	Fn  *compiler.Function
+	Env *object.Environment
+
+	globals *globals // the global variables of the program that defined it
+}
+
+func (c *Closure) Type() object.ObjectType { return object.FUNCTION_OBJ }
+func (c *Closure) Inspect() string         { return c.Fn.Inspect() }
+
+// globals are the global variables of a program, resolved to the slots of
+// the environment it runs in.
+type globals struct {
+	env   *object.Environment
+	names []string // the name of each variable, see compiler.Bytecode.Globals
+	slots []int    // the slot of each variable in env
+}
+
+// resolveGlobals resolves the global variables of code to slots of env.
+func resolveGlobals(code *compiler.Bytecode, env *object.Environment) *globals {
+	g := &globals{env: env, names: code.Globals, slots: make([]int, len(code.Globals))}
+	for i, name := range code.Globals {
+		g.slots[i] = env.Slot(name)
+	}
+	return g
+}
+
+// frame is the code of a program or of a function call in progress.
+type frame struct {
+	closure   *Closure // nil for a program
+	code      *compiler.Bytecode
+	globals   *globals
+	ip        int                 // the offset of the next instruction
+	base      int                 // the height of the stack when the call returns
+	callerEnv *object.Environment // the environment to go back to when the call returns
+}
+
+// pushFrame starts running code, in env. The stack above base is dropped when it returns.
+func (vm *VM) pushFrame(closure *Closure, code *compiler.Bytecode, globals *globals, base int, env *object.Environment) {
+	vm.frames = append(vm.frames, frame{closure: closure, code: code, globals: globals, base: base, callerEnv: vm.env})
+	vm.env = env
+	vm.last = nil
+	if closure != nil {
+		vm.calls++
+	}
+}
+
+// popFrame leaves the current code, going back to the environment of the caller.
+func (vm *VM) popFrame() {
+	top := len(vm.frames) - 1
+	f := &vm.frames[top]
+	vm.env = f.callerEnv
+	vm.stack = vm.stack[:f.base]
+	if f.closure != nil {
+		vm.calls--
+	}
+	vm.frames[top] = frame{}
+	vm.frames = vm.frames[:top]
+}
@@ -0,0 +1,450 @@
+// Package vm runs MeowLang programs compiled to bytecode by the compiler
+// package, on a stack machine.
+//
+// It is an alternative to the tree-walking interpreter, with the same
+// behaviour: both share an interpreter.Runtime for printing, napping, calling
+// built-in functions and enforcing limits, and the same operators.
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/AlyxPink/meowlang/ast"
+	"github.com/AlyxPink/meowlang/compiler"
+	"github.com/AlyxPink/meowlang/interpreter"
+	"github.com/AlyxPink/meowlang/object"
+	"github.com/AlyxPink/meowlang/token"
+)
+
+// VM runs compiled programs. Function calls run on the same VM as their
+// caller, so they share its output, input and settings.
+type VM struct {
+	rt     *interpreter.Runtime // the globals, input, output and limits of the program
+	env    *object.Environment  // the environment of the code being run
+	frames []frame              // the program and the function calls in progress, the innermost last
+	calls  int                  // the number of function calls in frames
+	stack  []object.Object
+	last   object.Object // the value of the last statement run in the current frame
+}
+
+// New creates a new VM. What the program prints is discarded.
+func New() *VM {
+	return NewWithOutput(io.Discard)
+}
+
+// NewWithOutput creates a new VM printing to out, as the program runs.
+// Diagnostics are written to out too.
+func NewWithOutput(out io.Writer) *VM {
+	return NewWithWriters(out, out)
+}
+
+// NewWithWriters creates a new VM printing to stdout, as the program runs,
+// and writing diagnostics to stderr.
+func NewWithWriters(stdout, stderr io.Writer) *VM {
+	return NewWithRuntime(interpreter.NewRuntime(stdout, stderr))
+}
+
+// NewWithEnv creates a new VM with a specified environment.
+// The environment must enclose one created by New for built-in functions to be available.
+func NewWithEnv(env *object.Environment) *VM {
+	return NewWithRuntime(interpreter.NewRuntimeWithEnv(env))
+}
+
+// NewWithRuntime creates a new VM running programs on rt.
+func NewWithRuntime(rt *interpreter.Runtime) *VM {
+	return &VM{rt: rt, env: rt.Env()}
+}
+
+// SetInput sets where the 'input' built-in function reads from, os.Stdin by default.
+func (vm *VM) SetInput(in io.Reader) {
+	vm.rt.SetInput(in)
+}
+
+// Env returns the environment the program runs in, e.g. to define variables before running it.
+func (vm *VM) Env() *object.Environment {
+	return vm.rt.Env()
+}
+
+// SetSleeper replaces the Sleeper used by 'nap', so tests can run without actually sleeping.
+func (vm *VM) SetSleeper(sleeper interpreter.Sleeper) {
+	vm.rt.SetSleeper(sleeper)
+}
+
+// SetNapUnit sets the duration of one unit of time passed to 'nap', e.g. time.Millisecond.
+func (vm *VM) SetNapUnit(unit time.Duration) {
+	vm.rt.SetNapUnit(unit)
+}
+
+// SetLimits sets the resources the program may use. Only the call depth is limited by default.
+func (vm *VM) SetLimits(limits interpreter.Limits) {
+	vm.rt.SetLimits(limits)
+}
+
+// Interpret compiles the given AST node and runs it, see Run.
+func (vm *VM) Interpret(node ast.Node) object.Object {
+	code, err := compiler.Compile(node)
+	if err != nil {
+		var compileErr *compiler.Error
+		if errors.As(err, &compileErr) {
+			return &object.Error{Message: compileErr.Message, Token: compileErr.Token, Err: err}
+		}
+		return &object.Error{Message: err.Error(), Err: err}
+	}
+	return vm.Run(code)
+}
+
+// InterpretContext compiles and runs the given AST node like Interpret. It
+// stops with an error as soon as ctx is done or one of the Limits is exceeded.
+func (vm *VM) InterpretContext(ctx context.Context, node ast.Node) object.Object {
+	defer vm.rt.Start(ctx)()
+	return vm.Interpret(node)
+}
+
+// Run runs compiled code and returns the value of its last statement, or the
+// Error that stopped it. It returns nil if the code has no statements.
+func (vm *VM) Run(code *compiler.Bytecode) object.Object {
+	depth := len(vm.frames)
+	vm.pushFrame(nil, code, resolveGlobals(code, vm.env), len(vm.stack), vm.env)
+	return vm.run(depth)
+}
+
+// Call calls a function or a built-in with the given arguments, e.g. a
+// function defined by a program that already ran. It returns an Error if fn
+// cannot be called with them.
+func (vm *VM) Call(fn object.Object, args ...object.Object) object.Object {
+	depth := len(vm.frames)
+	if result := vm.call(fn, args, len(vm.stack)); result != nil {
+		return result
+	}
+	return vm.run(depth)
+}
+
+// CallContext calls a function like Call. It stops with an error as soon as
+// ctx is done or one of the Limits is exceeded.
+func (vm *VM) CallContext(ctx context.Context, fn object.Object, args ...object.Object) object.Object {
+	defer vm.rt.Start(ctx)()
+	return vm.Call(fn, args...)
+}
+
+// call calls fn with args, which are on the stack above base if it is called
+// by the program. A built-in function returns its result right away. The
+// call of a closure pushes its frame and returns nil, its result is the
+// value returned by the frame.
+func (vm *VM) call(fn object.Object, args []object.Object, base int) object.Object {
+	switch fn := fn.(type) {
+	case *object.Builtin:
+		return vm.rt.CallBuiltin(token.Token{}, fn, append([]object.Object(nil), args...))
+	case *Closure:
+		params := fn.Fn.Parameters
+		if len(args) != len(params) {
+			return newError("wrong number of arguments: want=%d, got=%d", len(params), len(args))
+		}
+		if err := vm.rt.CheckCallDepth(token.Token{}, vm.calls); err != nil {
+			return err
+		}
+
+		code := fn.Fn.Code
+		env := object.NewSlotEnvironment(fn.Env, code.Locals, len(code.LocalNames))
+		for i := range params {
+			env.SetSlot(i, args[i])
+		}
+		vm.pushFrame(fn, code, fn.globals, base, env)
+		return nil
+	default:
+		return newError("not a function: %s", fn.Type())
+	}
+}
+
+// run runs the innermost frame, and the frames it pushes, until it returns
+// to depth frames. It returns the value returned by the frame it started
+// with, or the Error that stopped it.
+func (vm *VM) run(depth int) object.Object {
+	f := &vm.frames[len(vm.frames)-1]
+
+	for {
+		code := f.code
+		ip := f.ip
+		if ip >= len(code.Instructions) {
+			// The end of a program, functions end with OpReturn
+			result := vm.last
+			vm.popFrame()
+			return result
+		}
+
+		op := compiler.Opcode(code.Instructions[ip])
+		f.ip++
+
+		switch op {
+		case compiler.OpStep:
+			if index, err := vm.rt.Steps(f.readOperand()); err != nil {
+				err.Token = code.StepsAt(ip)[index].Token
+				return vm.fail(depth, err)
+			}
+
+		case compiler.OpConstant:
+			vm.push(code.Constants[f.readOperand()])
+		case compiler.OpNull:
+			vm.push(null)
+		case compiler.OpTrue:
+			vm.push(interpreter.TRUE)
+		case compiler.OpFalse:
+			vm.push(interpreter.FALSE)
+		case compiler.OpPop:
+			vm.last = vm.pop()
+		case compiler.OpUnset:
+			vm.last = nil
+
+		case compiler.OpGetGlobal:
+			g, index := f.globals, f.readOperand()
+			val, ok := g.env.GetSlot(g.slots[index], g.names[index])
+			if !ok {
+				return vm.fail(depth, f.locate(ip, errNotFound(g.names[index])))
+			}
+			vm.push(val)
+		case compiler.OpDefineGlobal:
+			g, index := f.globals, f.readOperand()
+			g.env.SetSlot(g.slots[index], vm.peek())
+		case compiler.OpAssignGlobal:
+			g, index := f.globals, f.readOperand()
+			if !g.env.AssignSlot(g.slots[index], g.names[index], vm.peek()) {
+				return vm.fail(depth, f.locate(ip, errUndeclared(g.names[index])))
+			}
+
+		case compiler.OpGetLocal:
+			slot := f.readOperand()
+			val, ok := vm.env.GetSlot(slot, code.LocalNames[slot])
+			if !ok {
+				return vm.fail(depth, f.locate(ip, errNotFound(code.LocalNames[slot])))
+			}
+			vm.push(val)
+		case compiler.OpDefineLocal:
+			vm.env.SetSlot(f.readOperand(), vm.peek())
+		case compiler.OpAssignLocal:
+			slot := f.readOperand()
+			if !vm.env.AssignSlot(slot, code.LocalNames[slot], vm.peek()) {
+				return vm.fail(depth, f.locate(ip, errUndeclared(code.LocalNames[slot])))
+			}
+
+		case compiler.OpGetName:
+			name := code.Names[f.readOperand()]
+			val, ok := vm.env.Get(name)
+			if !ok {
+				return vm.fail(depth, f.locate(ip, errNotFound(name)))
+			}
+			vm.push(val)
+		case compiler.OpAssignName:
+			name := code.Names[f.readOperand()]
+			if _, ok := vm.env.Assign(name, vm.peek()); !ok {
+				return vm.fail(depth, f.locate(ip, errUndeclared(name)))
+			}
+
+		case compiler.OpAdd, compiler.OpSub, compiler.OpMul, compiler.OpDiv,
+			compiler.OpEqual, compiler.OpNotEqual, compiler.OpLess, compiler.OpGreater,
+			compiler.OpLessEqual, compiler.OpGreaterEqual:
+			right := vm.pop()
+			left := vm.pop()
+			result := interpreter.Infix(token.Token{}, compiler.Operators[op], left, right)
+			if err, ok := result.(*object.Error); ok {
+				return vm.fail(depth, f.locate(ip, err))
+			}
+			vm.push(result)
+		case compiler.OpMinus, compiler.OpNot:
+			result := interpreter.Prefix(token.Token{}, compiler.Operators[op], vm.pop())
+			if err, ok := result.(*object.Error); ok {
+				return vm.fail(depth, f.locate(ip, err))
+			}
+			vm.push(result)
+
+		case compiler.OpJump:
+			f.ip = f.readOperand()
+		case compiler.OpJumpNotTruthy:
+			target := f.readOperand()
+			if !interpreter.IsTruthy(vm.pop()) {
+				f.ip = target
+			}
+		case compiler.OpJumpTruthy:
+			target := f.readOperand()
+			if interpreter.IsTruthy(vm.pop()) {
+				f.ip = target
+			}
+
+		case compiler.OpArray:
+			n := f.readOperand()
+			elements := make([]object.Object, n)
+			copy(elements, vm.stack[len(vm.stack)-n:])
+			vm.drop(n)
+			vm.push(&object.Array{Elements: elements})
+		case compiler.OpHashable:
+			if key := vm.peek(); !isHashable(key) {
+				return vm.fail(depth, f.locate(ip, newError("unusable as map key: %s", key.Type())))
+			}
+		case compiler.OpMap:
+			n := f.readOperand()
+			pairs := vm.stack[len(vm.stack)-2*n:]
+			m := object.NewMap()
+			for i := 0; i < len(pairs); i += 2 {
+				m.Set(pairs[i].(object.Hashable), pairs[i+1])
+			}
+			vm.drop(2 * n)
+			vm.push(m)
+		case compiler.OpIndex:
+			index := vm.pop()
+			left := vm.pop()
+			result := interpreter.Index(token.Token{}, left, index)
+			if err, ok := result.(*object.Error); ok {
+				return vm.fail(depth, f.locate(ip, err))
+			}
+			vm.push(result)
+		case compiler.OpSetIndex:
+			val := vm.pop()
+			index := vm.pop()
+			left := vm.pop()
+			result := interpreter.SetIndex(token.Token{}, left, index, val)
+			if err, ok := result.(*object.Error); ok {
+				return vm.fail(depth, f.locate(ip, err))
+			}
+			vm.push(result)
+		case compiler.OpDelete:
+			index := vm.pop()
+			left := vm.pop()
+			tokens := code.Tokens(ip)
+			result := interpreter.Delete(tokens[0], tokens[1], left, index)
+			if err, ok := result.(*object.Error); ok {
+				return vm.fail(depth, err)
+			}
+			vm.push(result)
+		case compiler.OpInterpolate:
+			n := f.readOperand()
+			var out strings.Builder
+			for _, part := range vm.stack[len(vm.stack)-n:] {
+				out.WriteString(part.Inspect())
+			}
+			vm.drop(n)
+			vm.push(&object.String{Value: out.String()})
+
+		case compiler.OpClosure:
+			fn := code.Constants[f.readOperand()].(*compiler.Function)
+			vm.push(&Closure{Fn: fn, Env: vm.env, globals: f.globals})
+		case compiler.OpCall:
+			n := f.readOperand()
+			base := len(vm.stack) - n - 1
+			result := vm.call(vm.stack[base], vm.stack[base+1:], base)
+			// The call pushed a frame, or a built-in function may have run
+			// the VM again with Call, so the frames may have moved
+			f = &vm.frames[len(vm.frames)-1]
+			if result == nil {
+				continue
+			}
+			if err, ok := result.(*object.Error); ok {
+				return vm.fail(depth, f.locate(ip, err))
+			}
+			vm.stack = vm.stack[:base]
+			vm.push(result)
+		case compiler.OpReturn:
+			if f.closure == nil {
+				return vm.fail(depth, f.locate(ip, newError("claw can only be used inside a function")))
+			}
+			result := vm.pop()
+			vm.popFrame()
+			if len(vm.frames) == depth {
+				return result
+			}
+			f = &vm.frames[len(vm.frames)-1]
+			vm.push(result)
+		case compiler.OpLast:
+			if vm.last == nil {
+				vm.push(null)
+			} else {
+				vm.push(vm.last)
+			}
+
+		case compiler.OpPrint:
+			if err := vm.rt.Print(token.Token{}, vm.pop()); err != nil {
+				return vm.fail(depth, f.locate(ip, err))
+			}
+			vm.push(null)
+		case compiler.OpNap:
+			if err := vm.rt.Nap(token.Token{}, vm.pop()); err != nil {
+				return vm.fail(depth, f.locate(ip, err))
+			}
+			vm.push(null)
+
+		default:
+			return vm.fail(depth, f.locate(ip, newError("unknown opcode: %d", op)))
+		}
+	}
+}
+
+// fail unwinds the frames back to depth, as the run stops with err.
+func (vm *VM) fail(depth int, err *object.Error) object.Object {
+	for len(vm.frames) > depth {
+		vm.popFrame()
+	}
+	return err
+}
+
+// locate sets the location of an error raised by the instruction at ip,
+// unless it is already located, e.g. by the function it called.
+func (f *frame) locate(ip int, err *object.Error) *object.Error {
+	if !err.Token.Pos.IsValid() {
+		err.Token = f.code.Token(ip)
+	}
+	return err
+}
+
+// readOperand reads the four-byte operand of the current instruction.
+func (f *frame) readOperand() int {
+	operand := compiler.ReadUint32(f.code.Instructions[f.ip:])
+	f.ip += 4
+	return int(operand)
+}
+
+func (vm *VM) push(obj object.Object) {
+	vm.stack = append(vm.stack, obj)
+}
+
+func (vm *VM) pop() object.Object {
+	top := len(vm.stack) - 1
+	obj := vm.stack[top]
+	vm.stack[top] = nil
+	vm.stack = vm.stack[:top]
+	return obj
+}
+
+func (vm *VM) peek() object.Object {
+	return vm.stack[len(vm.stack)-1]
+}
+
+// drop pops n values.
+func (vm *VM) drop(n int) {
+	top := len(vm.stack) - n
+	clear(vm.stack[top:])
+	vm.stack = vm.stack[:top]
+}
+
+// null is the value of statements without one. Nulls are all equal, so a single one is shared.
+var null = &object.Null{}
+
+func isHashable(obj object.Object) bool {
+	_, ok := obj.(object.Hashable)
+	return ok
+}
+
+func newError(format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...)}
+}
+
+func errNotFound(name string) *object.Error {
+	return newError("identifier not found: %s", name)
+}
+
+func errUndeclared(name string) *object.Error {
+	return newError("cannot assign to undeclared variable %s, declare it with lick first", name)
+}
+
+var _ interpreter.Engine = (*VM)(nil)
@@ -0,0 +1,59 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/AlyxPink/meowlang/lexer"
+	"github.com/AlyxPink/meowlang/object"
+	"github.com/AlyxPink/meowlang/parser"
+)
+
+// The behaviour of the vm is tested with the interpreter's tests, which run
+// on both backends. These tests check its internal state.
+
+func TestVM_FramesUnwind(t *testing.T) {
+	tests := []string{
+		`meow f(x) { hiss (x > 0) { f(x - 1) } claw x } f(2)`,
+		`meow f(x) { hiss (x > 0) { f(x - 1) } claw missing } f(2)`,
+		`lick xs = [1, 2, len(xs)]`,
+	}
+
+	for _, input := range tests {
+		l := lexer.NewLexer(input)
+		p := parser.NewParser(l.Tokenize())
+		program := p.ParseProgram()
+
+		vm := New()
+		vm.Interpret(program)
+
+		if len(vm.frames) != 0 || vm.calls != 0 {
+			t.Errorf("%q: expected no frame left, got %d with %d calls", input, len(vm.frames), vm.calls)
+		}
+		if len(vm.stack) != 0 {
+			t.Errorf("%q: expected an empty stack, got %d values", input, len(vm.stack))
+		}
+		if vm.env != vm.Env() {
+			t.Errorf("%q: expected to be back in the global environment", input)
+		}
+	}
+}
+
+func TestVM_CallAfterRun(t *testing.T) {
+	l := lexer.NewLexer(`lick n = 1 meow add(x) { n = n + x claw n }`)
+	p := parser.NewParser(l.Tokenize())
+	program := p.ParseProgram()
+
+	vm := New()
+	vm.Interpret(program)
+	add, _ := vm.Env().Get("add")
+
+	for _, expected := range []string{"3", "5"} {
+		result := vm.Call(add, object.NewInteger(2))
+		if result.Inspect() != expected {
+			t.Errorf("expected %s, got %s", expected, result.Inspect())
+		}
+	}
+	if len(vm.frames) != 0 || len(vm.stack) != 0 {
+		t.Errorf("expected no frame and an empty stack, got %d frames and %d values", len(vm.frames), len(vm.stack))
+	}
+}